@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+)
+
+const compareCommandName = "compare"
+
+// Exit codes for the compare command.
+const (
+	compareExitNoRegressions = 0
+	compareExitRegressions   = 1
+	compareExitError         = 2
+)
+
+type compareParams struct {
+	beforeFile        string
+	afterFile         string
+	failOnDisappeared bool
+}
+
+func (c *compareParams) Read(args []string) bool {
+	fs := flag.NewFlagSet(compareCommandName, flag.ContinueOnError)
+	fs.BoolVar(&c.failOnDisappeared, "fail-on-disappeared", false,
+		"also return a non-zero exit code if any tests from the old results are missing from the new results")
+	fs.Usage = func() {
+		helpers.MustFprintln(fs.Output(), "usage: sdk-test-harness compare [options] <old results file> <new results file>")
+		helpers.MustFprintln(fs.Output(), "results files can be in either the -json-results or the -junit format")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return false
+	}
+	c.beforeFile, c.afterFile = fs.Arg(0), fs.Arg(1)
+	return true
+}
+
+// runCompare implements the compare command, which reports differences between two results files.
+// It returns the process exit code.
+func runCompare(args []string) int {
+	var params compareParams
+	if !params.Read(args) {
+		return compareExitError
+	}
+	before, err := ldtest.ReadResultsFile(params.beforeFile)
+	if err != nil {
+		helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
+		return compareExitError
+	}
+	after, err := ldtest.ReadResultsFile(params.afterFile)
+	if err != nil {
+		helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
+		return compareExitError
+	}
+
+	comparison := ldtest.CompareResults(before, after)
+	if err := comparison.Write(os.Stdout); err != nil {
+		helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
+		return compareExitError
+	}
+	if len(comparison.Regressions()) != 0 || (params.failOnDisappeared && len(comparison.Disappeared) != 0) {
+		return compareExitRegressions
+	}
+	return compareExitNoRegressions
+}
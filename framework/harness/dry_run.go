@@ -0,0 +1,41 @@
+package harness
+
+import (
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/serviceinfo"
+)
+
+// NewDryRunTestHarness creates a TestHarness that is not connected to any test service, so that
+// the test suite can be walked without running the SDK. It behaves as if the test service had
+// returned the specified status information. Mock endpoints can be created as usual, but nothing
+// is listening for requests to them. Creating a test service entity always succeeds, but the entity
+// does nothing: every command succeeds with an empty response, so the test code can keep going and
+// start whatever subtests it would have started.
+func NewDryRunTestHarness(
+	testServiceInfo serviceinfo.TestServiceInfo,
+	testHarnessExternalHostname string,
+	testHarnessPort int,
+	testHarnessEnablePersistenceTests bool,
+	debugLogger framework.Logger,
+) *TestHarness {
+	if debugLogger == nil {
+		debugLogger = framework.NullLogger()
+	}
+	if !testHarnessEnablePersistenceTests {
+		testServiceInfo.Capabilities = withoutPersistenceCapabilities(testServiceInfo.Capabilities, debugLogger)
+	}
+	return &TestHarness{
+		testServiceInfo: testServiceInfo,
+		mockEndpoints: newMockEndpointsManager(
+			testHarnessExternalHostname,
+			map[string]int{"http": testHarnessPort, "https": testHarnessPort + 1},
+			debugLogger),
+		logger: debugLogger,
+		dryRun: true,
+	}
+}
+
+// IsDryRun returns true if the TestHarness was created with NewDryRunTestHarness.
+func (h *TestHarness) IsDryRun() bool {
+	return h.dryRun
+}
@@ -0,0 +1,35 @@
+package harness
+
+import (
+	"testing"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+	"github.com/launchdarkly/sdk-test-harness/v2/serviceinfo"
+
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunTestHarness(t *testing.T) {
+	info := serviceinfo.TestServiceInfo{TestServiceInfoBase: serviceinfo.TestServiceInfoBase{
+		Capabilities: framework.Capabilities{"a", servicedef.CapabilityPersistentDataStoreRedis},
+	}}
+	h := NewDryRunTestHarness(info, "testharness", 9998, false, nil)
+	assert.Equal(t, framework.Capabilities{"a"}, h.TestServiceInfo().Capabilities)
+
+	e := h.NewMockEndpoint(httphelpers.HandlerWithStatus(200), nil)
+	assert.Equal(t, "http://testharness:9998/endpoints/1", e.BaseURL())
+
+	assert.True(t, h.IsDryRun())
+
+	entity, err := h.NewTestServiceEntity(map[string]string{}, "entity", nil)
+	require.NoError(t, err)
+	response := map[string]string{"unchanged": "x"}
+	assert.NoError(t, entity.SendCommand("x", nil, &response))
+	assert.Equal(t, map[string]string{"unchanged": "x"}, response)
+	assert.NoError(t, entity.Close())
+	assert.NoError(t, h.StopService())
+}
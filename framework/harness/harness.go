@@ -85,16 +85,72 @@ type TestHarness struct {
 	mockEndpoints      *mockEndpointsManager
 	logger             framework.Logger
 	caFile             string
+	service            string
+	serviceProcess     *ServiceProcess
+	commandTimeout     time.Duration
+	liveness           *livenessChecker
+	dryRun             bool
 }
 
 // SetService tells the endpoint manager which protocol should be used when BaseURL() is called on a MockEndpoint.
 // Reaching into this  object is unfortunate, but since this is essentially a global variable from each
 // tests' perspective, this is the only way to modify it.
 // The service string should be one of 'http' or 'https'.
+// Tests that might run in parallel should use WithService instead.
 func (h *TestHarness) SetService(service string) {
 	h.mockEndpoints.SetService(service)
 }
 
+// WithService returns a TestHarness that is the same as this one, except that the mock endpoints it
+// creates always use the specified protocol ('http' or 'https') in BaseURL(), regardless of SetService.
+// Unlike SetService, this does not affect any other tests, so it is safe to use in parallel tests.
+func (h *TestHarness) WithService(service string) *TestHarness {
+	if _, ok := h.mockEndpoints.services[service]; !ok {
+		panic("programmer error: cannot set service to " + service + ", it has no port mapping")
+	}
+	h1 := *h
+	h1.service = service
+	return &h1
+}
+
+// SetServiceProcess tells the test harness that the test service is a process that it started with
+// StartServiceProcess. Before creating a test service entity, the harness will wait in case the
+// process is being restarted; and if the process has been restarted since an entity was created,
+// any further commands to that entity will fail, since the entity no longer exists.
+func (h *TestHarness) SetServiceProcess(p *ServiceProcess) {
+	h.serviceProcess = p
+	if h.liveness != nil {
+		h.liveness.setProcess(p)
+	}
+}
+
+// SetCommandTimeout sets the maximum time to wait for the test service to respond to a command that
+// is sent with TestServiceEntity.SendCommand or SendCommandWithParams, for entities that are created
+// after this call. If it is zero, there is no limit. The default is DefaultCommandTimeout.
+func (h *TestHarness) SetCommandTimeout(timeout time.Duration) {
+	h.commandTimeout = timeout
+}
+
+// CheckService returns an error if the test harness has detected that the test service is no longer
+// available: that is, it has stopped accepting connections for its status resource, or it was started
+// by the test harness and could not be restarted. This is meant to be used as
+// ldtest.TestConfiguration.CheckService.
+func (h *TestHarness) CheckService() error {
+	if h.liveness == nil {
+		return nil
+	}
+	return h.liveness.serviceLost()
+}
+
+// SetServiceOutputLogger redirects the output of the test service process, if there is one, to the
+// specified Logger; if logger is nil, the output goes back to where it went on startup. This has no
+// effect if the test service was not started by the test harness.
+func (h *TestHarness) SetServiceOutputLogger(logger framework.Logger) {
+	if h.serviceProcess != nil {
+		h.serviceProcess.SetOutputLogger(logger)
+	}
+}
+
 // CertificateAuthorityFile returns the file path of a CA cert used by the test harness when establishing a TLS
 // connection with the SDK under test.
 func (h *TestHarness) CertificateAuthorityFile() string {
@@ -123,7 +179,8 @@ func NewTestHarness(
 			testHarnessExternalHostname,
 			map[string]int{"http": testHarnessPort, "https": testHarnessPort + 1},
 			debugLogger),
-		logger: debugLogger,
+		logger:         debugLogger,
+		commandTimeout: DefaultCommandTimeout,
 	}
 
 	testServiceInfo, err := queryTestServiceInfo(testServiceBaseURL, statusQueryTimeout, startupOutput)
@@ -131,19 +188,8 @@ func NewTestHarness(
 		return nil, err
 	}
 
-	// If we aren't running persistence tests, remove the capabilities that would enable it.
 	if !testHarnessEnablePersistenceTests {
-		filteredCapabilities := make([]string, 0, len(testServiceInfo.Capabilities))
-		for _, c := range testServiceInfo.Capabilities {
-			if c == servicedef.CapabilityPersistentDataStoreRedis ||
-				c == servicedef.CapabilityPersistentDataStoreDynamoDB ||
-				c == servicedef.CapabilityPersistentDataStoreConsul {
-				debugLogger.Printf("Disabling capability %q because persistence tests are disabled", c)
-				continue
-			}
-			filteredCapabilities = append(filteredCapabilities, c)
-		}
-		testServiceInfo.Capabilities = filteredCapabilities
+		testServiceInfo.Capabilities = withoutPersistenceCapabilities(testServiceInfo.Capabilities, debugLogger)
 	}
 	h.testServiceInfo = testServiceInfo
 
@@ -160,9 +206,26 @@ func NewTestHarness(
 		startHTTPSServer(testHarnessPort+1, certInfo, http.HandlerFunc(h.serveHTTP))
 	}
 
+	h.liveness = newLivenessChecker(testServiceBaseURL, livenessCheckInterval, livenessCheckMaxFailures)
+
 	return h, nil
 }
 
+// If we aren't running persistence tests, remove the capabilities that would enable it.
+func withoutPersistenceCapabilities(capabilities framework.Capabilities, debugLogger framework.Logger) []string {
+	filteredCapabilities := make([]string, 0, len(capabilities))
+	for _, c := range capabilities {
+		if c == servicedef.CapabilityPersistentDataStoreRedis ||
+			c == servicedef.CapabilityPersistentDataStoreDynamoDB ||
+			c == servicedef.CapabilityPersistentDataStoreConsul {
+			debugLogger.Printf("Disabling capability %q because persistence tests are disabled", c)
+			continue
+		}
+		filteredCapabilities = append(filteredCapabilities, c)
+	}
+	return filteredCapabilities
+}
+
 // TestServiceInfo returns the initial status information received from the test service.
 func (h *TestHarness) TestServiceInfo() serviceinfo.TestServiceInfo {
 	return h.testServiceInfo
@@ -186,6 +249,9 @@ func (h *TestHarness) NewMockEndpoint(
 	if logger == nil {
 		logger = h.logger
 	}
+	if h.service != "" {
+		options = append([]MockEndpointOption{mockEndpointOptionService{h.service}}, options...)
+	}
 	return h.mockEndpoints.newMockEndpoint(handler, logger, options...)
 }
 
@@ -0,0 +1,114 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	livenessCheckInterval    = time.Second * 5
+	livenessCheckMaxFailures = 3
+)
+
+// livenessChecker periodically queries the test service's status resource, so that if the test
+// service has died, we can stop the test run instead of letting every remaining test fail.
+//
+// Only errors that mean nothing is listening anymore-- a refused or reset connection-- count as
+// failures. A status query that times out does not, because some test services can't answer status
+// queries while they are busy with a slow SDK operation, and that should not stop the test run.
+type livenessChecker struct {
+	url         string
+	process     *ServiceProcess
+	interval    time.Duration
+	maxFailures int
+	lostErr     error
+	closer      chan struct{}
+	closeOnce   sync.Once
+	lock        sync.Mutex
+}
+
+func newLivenessChecker(url string, interval time.Duration, maxFailures int) *livenessChecker {
+	c := &livenessChecker{
+		url:         url,
+		interval:    interval,
+		maxFailures: maxFailures,
+		closer:      make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// serviceLost returns a non-nil error if the test service is known to be unavailable.
+func (c *livenessChecker) serviceLost() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lostErr
+}
+
+func (c *livenessChecker) setProcess(p *ServiceProcess) {
+	c.lock.Lock()
+	c.process = p
+	c.lock.Unlock()
+}
+
+func (c *livenessChecker) close() {
+	c.closeOnce.Do(func() { close(c.closer) })
+}
+
+func (c *livenessChecker) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-c.closer:
+			return
+		case <-ticker.C:
+		}
+		c.lock.Lock()
+		process := c.process
+		c.lock.Unlock()
+		if process != nil {
+			// If the harness started the test service, it restarts it whenever it exits, so the
+			// service is only lost if a restart failed. Status queries during a restart don't count.
+			restarting, err := process.restartStatus()
+			if err != nil {
+				c.setLost(fmt.Errorf("test service could not be restarted: %w", err))
+				return
+			}
+			if restarting {
+				failures = 0
+				continue
+			}
+		}
+		_, _, err := doRequestWithTimeout("GET", c.url, nil, c.interval)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if !isConnectionLostError(err) {
+			continue
+		}
+		failures++
+		if failures >= c.maxFailures {
+			c.setLost(fmt.Errorf("test service did not respond to %d status queries in a row: %w", failures, err))
+			return
+		}
+	}
+}
+
+func (c *livenessChecker) setLost(err error) {
+	c.lock.Lock()
+	c.lostErr = err
+	c.lock.Unlock()
+}
+
+// isConnectionLostError returns true if the error means that the test service's port was not
+// accepting connections, or that the connection was dropped.
+func isConnectionLostError(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && isConnectionLostErrno(errno)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package harness
+
+import "syscall"
+
+func isConnectionLostErrno(errno syscall.Errno) bool {
+	return errno == syscall.ECONNREFUSED || errno == syscall.ECONNRESET
+}
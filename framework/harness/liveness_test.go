@@ -0,0 +1,43 @@
+package harness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessCheckerDetectsLostService(t *testing.T) {
+	server := httptest.NewServer(httphelpers.HandlerWithStatus(200))
+	defer server.Close()
+
+	c := newLivenessChecker(server.URL, time.Millisecond*10, 3)
+	defer c.close()
+
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, c.serviceLost())
+
+	server.Close()
+	require.Eventually(t, func() bool { return c.serviceLost() != nil }, time.Second, time.Millisecond*10)
+	assert.Contains(t, c.serviceLost().Error(), "did not respond to 3 status queries")
+}
+
+func TestLivenessCheckerDoesNotTreatSlowServiceAsLost(t *testing.T) {
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+
+	c := newLivenessChecker(server.URL, time.Millisecond*10, 2)
+	defer c.close()
+
+	time.Sleep(time.Millisecond * 200)
+	assert.NoError(t, c.serviceLost())
+}
@@ -0,0 +1,11 @@
+package harness
+
+import "syscall"
+
+// The syscall package does not define WSAECONNREFUSED, and its ECONNREFUSED is not what the net
+// package returns on Windows.
+const wsaeConnRefused syscall.Errno = 10061
+
+func isConnectionLostErrno(errno syscall.Errno) bool {
+	return errno == wsaeConnRefused || errno == syscall.WSAECONNRESET
+}
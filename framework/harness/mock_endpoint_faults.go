@@ -0,0 +1,286 @@
+package harness
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+)
+
+// errFaultInjected is returned by the response writer after a fault has closed the connection, so
+// that the endpoint's handler stops trying to write to it.
+var errFaultInjected = errors.New("connection was closed by injected fault") //nolint:gochecknoglobals
+
+// Fault describes a way in which a MockEndpoint should misbehave when it responds to a request,
+// regardless of what the endpoint's handler does. This allows tests to simulate network problems
+// for any kind of mock service without writing a special handler.
+//
+// The zero value of Fault means no fault. Fields can be combined; for instance, Latency can be
+// combined with any of the others. If both ResetAfterBytes and TruncateAfterBytes are set, the
+// connection is reset.
+type Fault struct {
+	// Latency is a delay before the handler is called. If the request is cancelled during the
+	// delay, the handler is not called.
+	Latency time.Duration
+
+	// Hang means that the endpoint will not respond at all; it holds the connection open until
+	// the request context is cancelled, either by the client or by IncomingRequestInfo.Cancel.
+	Hang bool
+
+	// ResetAfterBytes, if greater than zero, causes the connection to be reset (with a TCP RST)
+	// once this many bytes of the response body have been sent.
+	ResetAfterBytes int
+
+	// TruncateAfterBytes, if greater than zero, causes the connection to be closed normally once
+	// this many bytes of the response body have been sent. Since the response is incomplete (for
+	// a chunked response, the terminating chunk is never sent), the client should see an
+	// unexpected EOF rather than a clean end of stream.
+	TruncateAfterBytes int
+
+	// DripInterval, if greater than zero, causes the response body to be sent a few bytes at a
+	// time, with this delay between each write. DripChunkSize is the number of bytes per write;
+	// if it is zero, one byte is sent at a time.
+	DripInterval  time.Duration
+	DripChunkSize int
+}
+
+// FaultLatency is a shortcut for a Fault that only adds latency.
+func FaultLatency(latency time.Duration) Fault { return Fault{Latency: latency} }
+
+// FaultHang is a shortcut for a Fault that never responds.
+func FaultHang() Fault { return Fault{Hang: true} }
+
+// FaultResetAfterBytes is a shortcut for a Fault that resets the connection partway through the body.
+func FaultResetAfterBytes(n int) Fault { return Fault{ResetAfterBytes: n} }
+
+// FaultTruncateAfterBytes is a shortcut for a Fault that ends the response partway through the body.
+func FaultTruncateAfterBytes(n int) Fault { return Fault{TruncateAfterBytes: n} }
+
+// FaultSlowDrip is a shortcut for a Fault that sends the body chunkSize bytes at a time.
+func FaultSlowDrip(chunkSize int, interval time.Duration) Fault {
+	return Fault{DripChunkSize: chunkSize, DripInterval: interval}
+}
+
+func (f Fault) isDefined() bool { return f != Fault{} }
+
+type mockEndpointOptionFault struct {
+	requestIndex int
+	fault        Fault
+}
+
+func (o mockEndpointOptionFault) Configure(m *MockEndpoint) error {
+	if o.requestIndex < 0 {
+		m.fault = o.fault
+	} else {
+		m.setFaultForRequest(o.requestIndex, o.fault)
+	}
+	return nil
+}
+
+// MockEndpointFault is an option to inject a fault into every request to the endpoint. It is
+// equivalent to calling SetFault after creating the endpoint.
+func MockEndpointFault(fault Fault) MockEndpointOption {
+	return mockEndpointOptionFault{requestIndex: -1, fault: fault}
+}
+
+// MockEndpointFaultForRequest is an option to inject a fault into only one request to the
+// endpoint, where 0 is the first request. It is equivalent to calling SetFaultForRequest after
+// creating the endpoint.
+func MockEndpointFaultForRequest(requestIndex int, fault Fault) MockEndpointOption {
+	return mockEndpointOptionFault{requestIndex: requestIndex, fault: fault}
+}
+
+// SetFault causes all subsequent requests to the endpoint to have the specified fault, except for
+// requests that have their own fault set by SetFaultForRequest. Passing Fault{} removes it.
+func (e *MockEndpoint) SetFault(fault Fault) {
+	e.lock.Lock()
+	e.fault = fault
+	e.lock.Unlock()
+}
+
+// SetFaultForRequest causes the request with the specified index to have the specified fault,
+// where 0 is the first request that the endpoint receives. This overrides any fault set by
+// SetFault for that request.
+func (e *MockEndpoint) SetFaultForRequest(requestIndex int, fault Fault) {
+	e.lock.Lock()
+	e.setFaultForRequest(requestIndex, fault)
+	e.lock.Unlock()
+}
+
+func (e *MockEndpoint) setFaultForRequest(requestIndex int, fault Fault) {
+	if e.requestFaults == nil {
+		e.requestFaults = make(map[int]Fault)
+	}
+	e.requestFaults[requestIndex] = fault
+}
+
+// ClearFaults removes all faults that were set for the endpoint.
+func (e *MockEndpoint) ClearFaults() {
+	e.lock.Lock()
+	e.fault = Fault{}
+	e.requestFaults = nil
+	e.lock.Unlock()
+}
+
+// nextRequestFault increments the request counter and returns the fault, if any, for that request.
+// The caller must hold the endpoint's lock.
+func (e *MockEndpoint) nextRequestFault() Fault {
+	index := e.requestCount
+	e.requestCount++
+	if f, ok := e.requestFaults[index]; ok {
+		return f
+	}
+	return e.fault
+}
+
+// serveWithFault calls the handler after applying the fault. It returns without calling the
+// handler if the fault is a hang, or if the request is cancelled while waiting for latency.
+//
+// If the request context is cancelled before the handler is called, the connection is closed, so
+// the client sees an error rather than an empty response.
+func (e *MockEndpoint) serveWithFault(
+	fault Fault,
+	w http.ResponseWriter,
+	r *http.Request,
+	cancel context.CancelFunc,
+) {
+	if fault.Latency > 0 {
+		e.logger.Printf("Endpoint %q (%s) is delaying response by %s", e.description, e.basePath, fault.Latency)
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			closeConnection(w, false)
+			return
+		}
+	}
+	if fault.Hang {
+		e.logger.Printf("Endpoint %q (%s) is not responding until request is cancelled", e.description, e.basePath)
+		<-r.Context().Done()
+		closeConnection(w, false)
+		return
+	}
+	fw := &faultResponseWriter{
+		w:      w,
+		fault:  fault,
+		ctx:    r.Context(),
+		cancel: cancel,
+		onFault: func(desc string) {
+			e.logger.Printf("Endpoint %q (%s) %s", e.description, e.basePath, desc)
+		},
+	}
+	e.handler.ServeHTTP(fw, r)
+}
+
+// faultResponseWriter applies the body-related parts of a Fault to the response.
+type faultResponseWriter struct {
+	w       http.ResponseWriter
+	fault   Fault
+	ctx     context.Context
+	cancel  context.CancelFunc
+	onFault func(string)
+	written int
+	broken  bool
+}
+
+func (fw *faultResponseWriter) Header() http.Header { return fw.w.Header() }
+
+func (fw *faultResponseWriter) WriteHeader(status int) { fw.w.WriteHeader(status) }
+
+func (fw *faultResponseWriter) Write(data []byte) (int, error) {
+	if fw.broken {
+		return 0, errFaultInjected
+	}
+	limit := fw.fault.ResetAfterBytes
+	if limit <= 0 {
+		limit = fw.fault.TruncateAfterBytes
+	}
+	total := 0
+	for len(data) > 0 {
+		chunk := data
+		if fw.fault.DripInterval > 0 {
+			chunk = data[:min(len(data), max(fw.fault.DripChunkSize, 1))]
+		}
+		if limit > 0 && fw.written+len(chunk) >= limit {
+			n, _ := fw.w.Write(chunk[:limit-fw.written])
+			fw.written += n
+			total += n
+			fw.breakConnection()
+			return total, errFaultInjected
+		}
+		n, err := fw.w.Write(chunk)
+		fw.written += n
+		total += n
+		if err != nil {
+			return total, err
+		}
+		data = data[n:]
+		if fw.fault.DripInterval > 0 {
+			fw.Flush()
+			if len(data) > 0 {
+				select {
+				case <-time.After(fw.fault.DripInterval):
+				case <-fw.ctx.Done():
+					return total, fw.ctx.Err()
+				}
+			}
+		}
+	}
+	return total, nil
+}
+
+func (fw *faultResponseWriter) Flush() {
+	if f, ok := fw.w.(http.Flusher); ok && !fw.broken {
+		f.Flush()
+	}
+}
+
+// breakConnection closes the underlying connection, after making sure that everything written so
+// far has been sent. It also cancels the request context, so that a streaming handler will stop.
+func (fw *faultResponseWriter) breakConnection() {
+	fw.broken = true
+	reset := fw.fault.ResetAfterBytes > 0
+	fw.onFault(fmt.Sprintf("is %s connection after %d bytes",
+		helpers.IfElse(reset, "resetting", "closing"), fw.written))
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	closeConnection(fw.w, reset)
+	fw.cancel()
+}
+
+func (fw *faultResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := fw.w.(http.Hijacker); ok && !fw.broken {
+		return hj.Hijack()
+	}
+	return nil, nil, errors.New("Hijack is not available for this connection")
+}
+
+// closeConnection takes over the connection from the HTTP server and closes it, so the client does
+// not see a complete response. If reset is true, it makes the close send a TCP RST. This does
+// nothing if the ResponseWriter does not support hijacking (as in httptest.ResponseRecorder).
+func closeConnection(w http.ResponseWriter, reset bool) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	if reset {
+		netConn := conn
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			netConn = tlsConn.NetConn()
+		}
+		if tcpConn, ok := netConn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+	}
+	_ = conn.Close()
+}
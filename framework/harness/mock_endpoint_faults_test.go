@@ -0,0 +1,147 @@
+package harness
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const faultTestBody = "0123456789"
+
+func withFaultTestEndpoint(t *testing.T, action func(e *MockEndpoint, url string)) {
+	m := newMockEndpointsManager("testharness", map[string]int{"http": 9998}, framework.NullLogger())
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(faultTestBody))
+	})
+	e := m.newMockEndpoint(handler, framework.NullLogger())
+	server := httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	defer server.Close()
+	defer e.Close()
+	action(e, server.URL+e.basePath)
+}
+
+func doFaultTestRequest(t *testing.T, url string) (string, error) {
+	req, _ := http.NewRequest("GET", url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestMockEndpointFaultLatency(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFault(FaultLatency(time.Millisecond * 200))
+		startTime := time.Now()
+		body, err := doFaultTestRequest(t, url)
+		require.NoError(t, err)
+		assert.Equal(t, faultTestBody, body)
+		assert.GreaterOrEqual(t, time.Since(startTime), time.Millisecond*200)
+	})
+}
+
+func TestMockEndpointFaultHang(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFault(FaultHang())
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := doFaultTestRequest(t, url)
+			errCh <- err
+		}()
+		request := e.RequireConnection(t, time.Second)
+		select {
+		case <-errCh:
+			require.Fail(t, "request should not have completed before it was cancelled")
+		case <-time.After(time.Millisecond * 100):
+		}
+		request.Cancel()
+		select {
+		case err := <-errCh:
+			assert.Error(t, err)
+		case <-time.After(time.Second):
+			require.Fail(t, "timed out waiting for request to fail")
+		}
+	})
+}
+
+func TestMockEndpointFaultResetAfterBytes(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFault(FaultResetAfterBytes(4))
+		body, err := doFaultTestRequest(t, url)
+		assert.Error(t, err)
+		assert.Equal(t, faultTestBody[:4], body)
+	})
+}
+
+func TestMockEndpointFaultTruncateAfterBytes(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFault(FaultTruncateAfterBytes(4))
+		body, err := doFaultTestRequest(t, url)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, faultTestBody[:4], body)
+	})
+}
+
+func TestMockEndpointFaultSlowDrip(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFault(FaultSlowDrip(2, time.Millisecond*20))
+		startTime := time.Now()
+		body, err := doFaultTestRequest(t, url)
+		require.NoError(t, err)
+		assert.Equal(t, faultTestBody, body)
+		assert.GreaterOrEqual(t, time.Since(startTime), time.Millisecond*80) // 4 delays between 5 chunks
+	})
+}
+
+func TestMockEndpointFaultForRequestIndex(t *testing.T) {
+	withFaultTestEndpoint(t, func(e *MockEndpoint, url string) {
+		e.SetFaultForRequest(1, FaultTruncateAfterBytes(4))
+
+		body, err := doFaultTestRequest(t, url)
+		require.NoError(t, err)
+		assert.Equal(t, faultTestBody, body)
+
+		_, err = doFaultTestRequest(t, url)
+		assert.Error(t, err)
+
+		body, err = doFaultTestRequest(t, url)
+		require.NoError(t, err)
+		assert.Equal(t, faultTestBody, body)
+	})
+}
+
+func TestMockEndpointFaultOptionAndClearFaults(t *testing.T) {
+	m := newMockEndpointsManager("testharness", map[string]int{"http": 9998}, framework.NullLogger())
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(faultTestBody))
+	})
+	e := m.newMockEndpoint(handler, framework.NullLogger(),
+		MockEndpointFault(FaultResetAfterBytes(1)), MockEndpointFaultForRequest(0, FaultLatency(time.Millisecond)))
+	server := httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	defer server.Close()
+	defer e.Close()
+	url := server.URL + e.basePath
+
+	body, err := doFaultTestRequest(t, url)
+	require.NoError(t, err)
+	assert.Equal(t, faultTestBody, body)
+
+	_, err = doFaultTestRequest(t, url)
+	assert.Error(t, err)
+
+	e.ClearFaults()
+	body, err = doFaultTestRequest(t, url)
+	require.NoError(t, err)
+	assert.Equal(t, faultTestBody, body)
+}
@@ -40,18 +40,22 @@ type mockEndpointsManager struct {
 
 // MockEndpoint represents an endpoint that can receive requests.
 type MockEndpoint struct {
-	owner       *mockEndpointsManager
-	id          string
-	description string
-	basePath    string
-	handler     http.Handler
-	contextFn   func(context.Context) context.Context
-	newConns    chan IncomingRequestInfo
-	activeConn  *IncomingRequestInfo
-	cancels     []*context.CancelFunc
-	logger      framework.Logger
-	lock        sync.Mutex
-	closing     sync.Once
+	owner         *mockEndpointsManager
+	service       string // if empty, the owner's current service is used
+	id            string
+	description   string
+	basePath      string
+	handler       http.Handler
+	contextFn     func(context.Context) context.Context
+	newConns      chan IncomingRequestInfo
+	activeConn    *IncomingRequestInfo
+	cancels       []*context.CancelFunc
+	fault         Fault
+	requestFaults map[int]Fault
+	requestCount  int
+	logger        framework.Logger
+	lock          sync.Mutex
+	closing       sync.Once
 }
 
 // MockEndpointOption is the interface for options to NewMockEndpoint.
@@ -88,6 +92,15 @@ func MockEndpointDescription(description string) MockEndpointOption {
 	return mockEndpointOptionDescription{description}
 }
 
+type mockEndpointOptionService struct {
+	service string
+}
+
+func (o mockEndpointOptionService) Configure(m *MockEndpoint) error {
+	m.service = o.service
+	return nil
+}
+
 // IncomingRequestInfo contains information about an HTTP request sent by the test service
 // to one of the mock endpoints.
 type IncomingRequestInfo struct {
@@ -117,11 +130,15 @@ func (m *mockEndpointsManager) SetService(service string) {
 	m.service = service
 }
 func (m *mockEndpointsManager) BaseURL() string {
-	port, ok := m.services[m.service]
+	return m.baseURLForService(m.service)
+}
+
+func (m *mockEndpointsManager) baseURLForService(service string) string {
+	port, ok := m.services[service]
 	if !ok {
-		panic("programmer error: service " + m.service + " has no port mapping")
+		panic("programmer error: service " + service + " has no port mapping")
 	}
-	return m.service + "://" + m.host + ":" + fmt.Sprintf("%d", port)
+	return service + "://" + m.host + ":" + fmt.Sprintf("%d", port)
 }
 
 func (m *mockEndpointsManager) newMockEndpoint(
@@ -214,6 +231,7 @@ func (m *mockEndpointsManager) serveHTTP(w http.ResponseWriter, r *http.Request)
 	cancellerPtr := &canceller
 	e.cancels = append(e.cancels, cancellerPtr)
 	newConns := e.newConns
+	fault := e.nextRequestFault()
 	e.lock.Unlock()
 
 	if newConns == nil {
@@ -231,7 +249,11 @@ func (m *mockEndpointsManager) serveHTTP(w http.ResponseWriter, r *http.Request)
 	}
 
 	wrappedWriter := wrappedResponseWriter{w: w}
-	e.handler.ServeHTTP(&wrappedWriter, transformedReq)
+	if fault.isDefined() {
+		e.serveWithFault(fault, &wrappedWriter, transformedReq, canceller)
+	} else {
+		e.handler.ServeHTTP(&wrappedWriter, transformedReq)
+	}
 
 	switch wrappedWriter.status {
 	case http.StatusNotFound:
@@ -254,7 +276,11 @@ func (m *mockEndpointsManager) serveHTTP(w http.ResponseWriter, r *http.Request)
 
 // BaseURL returns the base path of the mock endpoint.
 func (e *MockEndpoint) BaseURL() string {
-	joined, err := url.JoinPath(e.owner.BaseURL(), e.basePath)
+	baseURL := e.owner.BaseURL()
+	if e.service != "" {
+		baseURL = e.owner.baseURLForService(e.service)
+	}
+	joined, err := url.JoinPath(baseURL, e.basePath)
 	if err != nil {
 		panic("invalid sdk-test-harness base URL: " + err.Error())
 	}
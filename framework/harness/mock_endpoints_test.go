@@ -46,6 +46,24 @@ func TestMockEndpointServesRequest(t *testing.T) {
 	}
 }
 
+func TestMockEndpointUsesHarnessService(t *testing.T) {
+	m := newMockEndpointsManager("testharness", map[string]int{"http": 9998, "https": 9999}, framework.NullLogger())
+	h := &TestHarness{mockEndpoints: m, logger: framework.NullLogger()}
+
+	e1 := h.NewMockEndpoint(httphelpers.HandlerWithStatus(200), nil)
+	e2 := h.WithService("https").NewMockEndpoint(httphelpers.HandlerWithStatus(200), nil)
+
+	assert.Equal(t, "http://testharness:9998/endpoints/1", e1.BaseURL())
+	assert.Equal(t, "https://testharness:9999/endpoints/2", e2.BaseURL())
+
+	m.SetService("https")
+	assert.Equal(t, "https://testharness:9999/endpoints/1", e1.BaseURL())
+	assert.Equal(t, "https://testharness:9999/endpoints/2", e2.BaseURL())
+
+	m.SetService("http")
+	assert.Equal(t, "https://testharness:9999/endpoints/2", e2.BaseURL())
+}
+
 func TestMockEndpointReceivesSubpath(t *testing.T) {
 	m := newMockEndpointsManager("testharness", map[string]int{"http": 9998}, framework.NullLogger())
 
@@ -0,0 +1,227 @@
+package harness
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+)
+
+const serviceProcessStopTimeout = time.Second * 10
+
+// ServiceProcess is a test service that was started by the test harness, rather than by whoever
+// started the test harness. The harness captures its output, and restarts it if it exits unexpectedly.
+//
+// To use it, call StartServiceProcess before NewTestHarness, and then pass it to
+// TestHarness.SetServiceProcess.
+type ServiceProcess struct {
+	command       string
+	statusURL     string
+	statusTimeout time.Duration
+	output        io.Writer
+	outputLogger  framework.Logger
+	cmd           *exec.Cmd
+	exited        chan struct{}
+	ready         chan struct{}
+	startErr      error
+	generation    int
+	stopping      bool
+	lock          sync.Mutex
+}
+
+// StartServiceProcess runs the specified shell command to start the test service, and waits until
+// the service responds to a request for its status resource at statusURL.
+//
+// Until SetOutputLogger is called, each line of the service's standard output and standard error is
+// copied to the output writer.
+func StartServiceProcess(
+	command string,
+	statusURL string,
+	statusTimeout time.Duration,
+	output io.Writer,
+) (*ServiceProcess, error) {
+	p := &ServiceProcess{
+		command:       command,
+		statusURL:     statusURL,
+		statusTimeout: statusTimeout,
+		output:        output,
+		ready:         make(chan struct{}),
+	}
+	helpers.MustFprintf(output, "Starting test service: %s\n", command)
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	if err := p.waitForStatus(output); err != nil {
+		p.Stop()
+		return nil, err
+	}
+	go p.monitor()
+	return p, nil
+}
+
+// SetOutputLogger causes all subsequent output from the test service to be sent to the specified
+// Logger instead of the output writer. If logger is nil, output goes to the output writer again.
+func (p *ServiceProcess) SetOutputLogger(logger framework.Logger) {
+	p.lock.Lock()
+	p.outputLogger = logger
+	p.lock.Unlock()
+}
+
+// Generation returns a number that is incremented every time the test service is restarted. Any
+// entities that were created in a previous generation no longer exist.
+func (p *ServiceProcess) Generation() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.generation
+}
+
+// WaitUntilReady blocks until the test service is running and responding to requests, in case it
+// is being restarted. It returns the current generation, or an error if the service could not be
+// restarted or has been stopped.
+func (p *ServiceProcess) WaitUntilReady() (int, error) {
+	p.lock.Lock()
+	ready := p.ready
+	p.lock.Unlock()
+	<-ready
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.startErr != nil {
+		return 0, p.startErr
+	}
+	if p.stopping {
+		return 0, errors.New("test service has been stopped")
+	}
+	return p.generation, nil
+}
+
+// restartStatus returns true if the process is being restarted, or an error if it could not be
+// restarted.
+func (p *ServiceProcess) restartStatus() (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	select {
+	case <-p.ready:
+		return false, p.startErr
+	default:
+		return true, nil
+	}
+}
+
+// Stop interrupts the test service process, and any other processes that its command started, and
+// waits for it to exit. If it has not exited within a reasonable time, they are all killed.
+func (p *ServiceProcess) Stop() {
+	p.lock.Lock()
+	p.stopping = true
+	cmd, exited := p.cmd, p.exited
+	p.lock.Unlock()
+
+	if interruptProcessGroup(cmd) != nil {
+		killProcessGroup(cmd)
+	}
+	select {
+	case <-exited:
+	case <-time.After(serviceProcessStopTimeout):
+		killProcessGroup(cmd)
+		<-exited
+	}
+}
+
+func (p *ServiceProcess) start() error {
+	cmd := shellCommand(p.command)
+	// We use our own pipes, rather than cmd.StdoutPipe(), so that cmd.Wait doesn't have to wait for
+	// them to be closed: if the command started some other process that inherited them, that process
+	// might still be running after the command has exited.
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	cmd.Stdout, cmd.Stderr = stdoutWriter, stderrWriter
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start test service: %w", err)
+	}
+	go p.copyOutput(stdoutReader, "[test service] ")
+	go p.copyOutput(stderrReader, "[test service stderr] ")
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		_ = stdoutWriter.Close()
+		_ = stderrWriter.Close()
+		close(exited)
+	}()
+
+	p.lock.Lock()
+	p.cmd = cmd
+	p.exited = exited
+	p.lock.Unlock()
+	return nil
+}
+
+// waitForStatus waits for the status resource to respond, and then unblocks WaitUntilReady.
+func (p *ServiceProcess) waitForStatus(output io.Writer) error {
+	_, err := queryTestServiceInfo(p.statusURL, p.statusTimeout, output)
+	p.lock.Lock()
+	p.startErr = err
+	close(p.ready)
+	p.lock.Unlock()
+	return err
+}
+
+func (p *ServiceProcess) monitor() {
+	for {
+		p.lock.Lock()
+		cmd, exited := p.cmd, p.exited
+		p.lock.Unlock()
+		<-exited
+
+		p.lock.Lock()
+		if p.stopping {
+			p.lock.Unlock()
+			return
+		}
+		p.generation++
+		p.ready = make(chan struct{})
+		p.lock.Unlock()
+
+		p.writeOutput("test service exited unexpectedly; restarting")
+		// If the command started other processes, they might still be running and using the port.
+		killProcessGroup(cmd)
+		if err := p.start(); err != nil {
+			p.lock.Lock()
+			p.startErr = err
+			close(p.ready)
+			p.lock.Unlock()
+			p.writeOutput(err.Error())
+			return
+		}
+		// The status query's progress output would disrupt the console output of the test run, so we
+		// discard it; if the service doesn't come back, the error from WaitUntilReady will say why.
+		if err := p.waitForStatus(io.Discard); err != nil {
+			p.writeOutput(fmt.Sprintf("failed to restart test service: %s", err))
+			return
+		}
+	}
+}
+
+func (p *ServiceProcess) copyOutput(r io.Reader, prefix string) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.writeOutput(prefix + scanner.Text())
+	}
+}
+
+func (p *ServiceProcess) writeOutput(line string) {
+	p.lock.Lock()
+	logger := p.outputLogger
+	p.lock.Unlock()
+	if logger != nil {
+		logger.Println(line)
+	} else {
+		helpers.MustFprintln(p.output, line)
+	}
+}
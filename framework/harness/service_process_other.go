@@ -0,0 +1,24 @@
+//go:build !windows
+
+package harness
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand returns a command that runs the specified shell command in a new process group, so
+// that if the shell starts other processes, we can stop all of them and not just the shell.
+func shellCommand(command string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd
+}
+
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
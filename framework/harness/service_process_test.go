@@ -0,0 +1,168 @@
+package harness
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	serviceProcessHelperEnvVar = "SDK_TEST_HARNESS_SERVICE_PROCESS_HELPER"
+	serviceProcessPortEnvVar   = "SDK_TEST_HARNESS_SERVICE_PROCESS_PORT"
+)
+
+// TestServiceProcessHelper is not a real test: it is run as a subprocess by the other tests in this
+// file, to act as a minimal test service.
+func TestServiceProcessHelper(t *testing.T) {
+	if os.Getenv(serviceProcessHelperEnvVar) == "" {
+		t.Skip("only used as a subprocess")
+	}
+	fmt.Println("service is starting")
+	_, _ = fmt.Fprintln(os.Stderr, "service stderr message")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crash":
+			fmt.Println("service is crashing")
+			os.Exit(1)
+		default:
+			_, _ = w.Write([]byte(`{"capabilities":[]}`))
+		}
+	})
+	_ = http.ListenAndServe("127.0.0.1:"+os.Getenv(serviceProcessPortEnvVar), handler) //nolint:gosec
+	os.Exit(1)
+}
+
+type capturedLines struct {
+	lines []string
+	lock  sync.Mutex
+}
+
+func (c *capturedLines) Write(data []byte) (int, error) {
+	c.add(string(data))
+	return len(data), nil
+}
+
+func (c *capturedLines) Println(args ...interface{}) {
+	c.add(fmt.Sprintln(args...))
+}
+
+func (c *capturedLines) Printf(message string, args ...interface{}) {
+	c.add(fmt.Sprintf(message, args...))
+}
+
+func (c *capturedLines) add(s string) {
+	c.lock.Lock()
+	c.lines = append(c.lines, strings.TrimRight(s, "\n"))
+	c.lock.Unlock()
+}
+
+func (c *capturedLines) has(line string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, l := range c.lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+// startHelperServiceProcess starts TestServiceProcessHelper with a shell command that is made by
+// substituting the helper's command line into commandFormat.
+func startHelperServiceProcess(t *testing.T, output *capturedLines, commandFormat string) (*ServiceProcess, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this test uses a Unix shell command")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	t.Setenv(serviceProcessHelperEnvVar, "1")
+	t.Setenv(serviceProcessPortEnvVar, fmt.Sprint(port))
+	command := fmt.Sprintf(commandFormat, fmt.Sprintf("'%s' -test.run='^TestServiceProcessHelper$'", os.Args[0]))
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	p, err := StartServiceProcess(command, url, time.Second*10, output)
+	require.NoError(t, err)
+	t.Cleanup(p.Stop)
+	return p, url
+}
+
+func TestServiceProcessCapturesOutput(t *testing.T) {
+	var output, logger capturedLines
+	p, url := startHelperServiceProcess(t, &output, "exec %s")
+
+	assert.Eventually(t, func() bool {
+		return output.has("[test service] service is starting") &&
+			output.has("[test service stderr] service stderr message")
+	}, time.Second, time.Millisecond*10)
+
+	p.SetOutputLogger(&logger)
+	_, _, _ = doRequest("GET", url+"/crash", nil)
+	assert.Eventually(t, func() bool {
+		return logger.has("[test service] service is crashing")
+	}, time.Second*5, time.Millisecond*10)
+	assert.False(t, output.has("[test service] service is crashing"))
+}
+
+func TestServiceProcessIsRestartedAfterExiting(t *testing.T) {
+	var output capturedLines
+	p, url := startHelperServiceProcess(t, &output, "exec %s")
+
+	generation, err := p.WaitUntilReady()
+	require.NoError(t, err)
+	assert.Equal(t, 0, generation)
+
+	e := &TestServiceEntity{resourceURL: url + "/entity", logger: &output, process: p, generation: generation}
+
+	_, _, _ = doRequest("GET", url+"/crash", nil)
+	require.Eventually(t, func() bool { return p.Generation() == 1 }, time.Second*5, time.Millisecond*10)
+
+	generation, err = p.WaitUntilReady()
+	require.NoError(t, err)
+	assert.Equal(t, 1, generation)
+	assert.True(t, output.has("test service exited unexpectedly; restarting"))
+	_, _, err = doRequest("GET", url, nil)
+	assert.NoError(t, err)
+
+	err = e.SendCommand("x", nil, nil)
+	assert.ErrorContains(t, err, "test service was restarted")
+	assert.NoError(t, e.Close())
+}
+
+func TestServiceProcessStop(t *testing.T) {
+	var output capturedLines
+	p, url := startHelperServiceProcess(t, &output, "exec %s")
+
+	p.Stop()
+
+	_, _, err := doRequest("GET", url, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, p.Generation(), "service should not have been restarted")
+	_, err = p.WaitUntilReady()
+	assert.Error(t, err)
+}
+
+func TestServiceProcessStopStopsProcessesStartedByCommand(t *testing.T) {
+	var output capturedLines
+	// The shell has to start the service as a child process, rather than replacing itself with it,
+	// since there is another command after it.
+	p, url := startHelperServiceProcess(t, &output, "%s; echo service has exited")
+
+	p.Stop()
+
+	assert.Eventually(t, func() bool {
+		_, _, err := doRequest("GET", url, nil)
+		return err != nil
+	}, time.Second*5, time.Millisecond*10, "service is still running")
+}
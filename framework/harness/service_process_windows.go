@@ -0,0 +1,20 @@
+package harness
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
+
+// os.Interrupt is not supported on Windows, so there we can only kill the process.
+func interruptProcessGroup(*exec.Cmd) error {
+	return errors.New("interrupting a process is not supported on Windows")
+}
+
+// On Windows, this only kills the process that runs the shell command, not any others that it started.
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
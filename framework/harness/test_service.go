@@ -2,6 +2,7 @@ package harness
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,8 +24,15 @@ type TestServiceEntity struct {
 	resourceURL string
 	logger      framework.Logger
 	closeOnce   sync.Once
+	process     *ServiceProcess
+	generation  int
+	timeout     time.Duration
+	dryRun      bool
 }
 
+// DefaultCommandTimeout is the default value for TestHarness.SetCommandTimeout.
+const DefaultCommandTimeout = time.Second * 30
+
 func queryTestServiceInfo(url string, timeout time.Duration, output io.Writer) (serviceinfo.TestServiceInfo, error) {
 	helpers.MustFprintf(output, "Connecting to test service at %s", url)
 
@@ -39,11 +47,11 @@ func queryTestServiceInfo(url string, timeout time.Duration, output io.Writer) (
 				return serviceinfo.Empty(), nil
 			}
 			helpers.MustFprintf(output, "Status query returned metadata: %s\n", string(respData))
-			var base serviceinfo.TestServiceInfoBase
-			if err := json.Unmarshal(respData, &base); err != nil {
+			info, err := serviceinfo.Parse(respData)
+			if err != nil {
 				return serviceinfo.Empty(), fmt.Errorf("malformed status response from test service: %s", string(respData))
 			}
-			return serviceinfo.TestServiceInfo{TestServiceInfoBase: base, FullData: respData}, nil
+			return info, nil
 		}
 		if !time.Now().Before(deadline) {
 			return serviceinfo.Empty(), fmt.Errorf("timed out, result of last query was: %w", err)
@@ -54,6 +62,12 @@ func queryTestServiceInfo(url string, timeout time.Duration, output io.Writer) (
 
 // StopService tells the test service that it should exit.
 func (h *TestHarness) StopService() error {
+	if h.dryRun {
+		return nil
+	}
+	if h.liveness != nil {
+		h.liveness.close()
+	}
 	_, _, _ = doRequest("DELETE", h.testServiceBaseURL, nil)
 	// It's normal for the request to return an I/O error if the service immediately quit before sending a response
 	return nil
@@ -74,6 +88,17 @@ func (h *TestHarness) NewTestServiceEntity(
 	if logger == nil {
 		logger = framework.NullLogger()
 	}
+	if h.dryRun {
+		logger.Printf("Not creating test service entity (%s), because this is a dry run", description)
+		return &TestServiceEntity{logger: logger, dryRun: true}, nil
+	}
+	generation := 0
+	if h.serviceProcess != nil {
+		var err error
+		if generation, err = h.serviceProcess.WaitUntilReady(); err != nil {
+			return nil, err
+		}
+	}
 
 	data, err := json.Marshal(entityParams)
 	if err != nil {
@@ -96,6 +121,9 @@ func (h *TestHarness) NewTestServiceEntity(
 	e := &TestServiceEntity{
 		resourceURL: resourceURL,
 		logger:      logger,
+		process:     h.serviceProcess,
+		generation:  generation,
+		timeout:     h.commandTimeout,
 	}
 
 	return e, nil
@@ -106,11 +134,23 @@ func (h *TestHarness) NewTestServiceEntity(
 // to work), and also that we consistently check for HTTP errors: unlike the HTTPClient
 // methods, it returns an error if the HTTP status is not 2xx.
 func doRequest(method, url string, body []byte) ([]byte, http.Header, error) {
+	return doRequestWithTimeout(method, url, body, 0)
+}
+
+// doRequestWithTimeout is the same as doRequest, except that if timeout is nonzero, it returns an
+// error if the test service has not finished responding within that time.
+func doRequestWithTimeout(method, url string, body []byte, timeout time.Duration) ([]byte, http.Header, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewBuffer(body)
 	}
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -119,6 +159,9 @@ func doRequest(method, url string, body []byte) ([]byte, http.Header, error) {
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, fmt.Errorf("test service did not respond to %s %s within %s", method, url, timeout)
+		}
 		return nil, nil, err
 	}
 	var respBody []byte
@@ -143,6 +186,13 @@ func doRequest(method, url string, body []byte) ([]byte, http.Header, error) {
 func (e *TestServiceEntity) Close() error {
 	var err error
 	e.closeOnce.Do(func() {
+		if e.dryRun {
+			return
+		}
+		if e.isStale() {
+			e.logger.Printf("Not closing %s, because the test service has been restarted", e.resourceURL)
+			return
+		}
 		e.logger.Printf("Closing %s", e.resourceURL)
 		_, _, err = doRequest("DELETE", e.resourceURL, nil)
 		if err != nil {
@@ -176,7 +226,13 @@ func (e *TestServiceEntity) SendCommandWithParams(
 	}
 	data, _ := json.Marshal(allParams)
 	logger.Printf("Sending command: %s", string(data))
-	body, _, err := doRequest("POST", e.resourceURL, data)
+	if e.dryRun {
+		return nil // leave responseOut unchanged
+	}
+	if e.isStale() {
+		return errors.New("test service was restarted after this entity was created, probably because it crashed")
+	}
+	body, _, err := doRequestWithTimeout("POST", e.resourceURL, data, e.timeout)
 	if err != nil {
 		return err
 	}
@@ -191,3 +247,9 @@ func (e *TestServiceEntity) SendCommandWithParams(
 	}
 	return nil
 }
+
+// isStale returns true if the entity was created by an earlier instance of a test service process
+// that has since been restarted, so it no longer exists.
+func (e *TestServiceEntity) isStale() bool {
+	return e.process != nil && e.process.Generation() != e.generation
+}
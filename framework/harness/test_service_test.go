@@ -0,0 +1,23 @@
+package harness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendCommandTimesOut(t *testing.T) {
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+
+	e := &TestServiceEntity{resourceURL: server.URL, logger: &capturedLines{}, timeout: time.Millisecond * 50}
+	err := e.SendCommand("x", nil, nil)
+	assert.ErrorContains(t, err, "test service did not respond to POST "+server.URL+" within 50ms")
+}
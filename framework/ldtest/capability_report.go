@@ -0,0 +1,115 @@
+package ldtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+)
+
+// WriteCapabilityReport writes a report of which tests were gated by each capability, based on the
+// calls to T.RequireCapability and T.RequireCapabilities during a test run, and whether those tests
+// passed, failed, or were skipped.
+//
+// knownCapabilities is every capability that the test suite defines; supportedCapabilities is the
+// list that the test service reported. The report also lists any supported capabilities that are not
+// known, which are probably typos in the test service, and any that no test checked in this run.
+func WriteCapabilityReport(
+	out io.Writer,
+	results Results,
+	knownCapabilities []string,
+	supportedCapabilities framework.Capabilities,
+) {
+	outcomes := make(map[string]string)
+	for _, r := range results.Tests {
+		outcomes[r.TestID.String()] = "passed"
+	}
+	for _, r := range results.NonCriticalFailures {
+		outcomes[r.TestID.String()] = "failed (non-critical)"
+	}
+	for _, r := range results.Failures {
+		outcomes[r.TestID.String()] = "failed"
+	}
+	outcomeOf := func(id TestID) string {
+		if outcome, ok := outcomes[id.String()]; ok {
+			return outcome
+		}
+		return "skipped" // tests that were skipped don't have a TestResult
+	}
+
+	known := make(map[string]bool)
+	allNames := append([]string(nil), knownCapabilities...)
+	for _, c := range knownCapabilities {
+		known[c] = true
+	}
+	for c := range results.CapabilityChecks {
+		if !known[c] {
+			allNames = append(allNames, c) // shouldn't happen unless a test uses a capability that isn't a constant
+			known[c] = true
+		}
+	}
+	sort.Strings(allNames)
+
+	helpers.MustFprintln(out, "Capability coverage report")
+	helpers.MustFprintln(out)
+	var unchecked []string
+	for _, c := range allNames {
+		ids := uniqueTestIDs(results.CapabilityChecks[c])
+		if len(ids) == 0 {
+			unchecked = append(unchecked, c)
+			continue
+		}
+		counts := make(map[string]int)
+		for _, id := range ids {
+			counts[outcomeOf(id)]++
+		}
+		var summary []string
+		for _, outcome := range []string{"passed", "failed", "failed (non-critical)", "skipped"} {
+			if counts[outcome] != 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", counts[outcome], outcome))
+			}
+		}
+		helpers.MustFprintf(out, "%s (%s): %s\n", c,
+			helpers.IfElse(supportedCapabilities.Has(c), "supported", "not supported"), strings.Join(summary, ", "))
+		for _, id := range ids {
+			helpers.MustFprintf(out, "  [%s] %s\n", id, outcomeOf(id))
+		}
+	}
+	helpers.MustFprintln(out)
+
+	if len(unchecked) != 0 {
+		helpers.MustFprintln(out, "Capabilities that were not required by any test in this run:")
+		for _, c := range unchecked {
+			helpers.MustFprintf(out, "  %s%s\n", c, helpers.IfElse(supportedCapabilities.Has(c), " (supported)", ""))
+		}
+		helpers.MustFprintln(out)
+	}
+
+	var unknown []string
+	for _, c := range supportedCapabilities {
+		if !known[c] {
+			unknown = append(unknown, c)
+		}
+	}
+	if len(unknown) != 0 {
+		helpers.MustFprintln(out,
+			"WARNING: the test service reported capabilities that are not used by any test (possible typos):")
+		helpers.MustFprintf(out, "  %s\n", strings.Join(unknown, ", "))
+		helpers.MustFprintln(out)
+	}
+}
+
+func uniqueTestIDs(ids []TestID) []TestID {
+	var ret []TestID
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if !seen[id.String()] {
+			seen[id.String()] = true
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,51 @@
+package ldtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilityReport(t *testing.T) {
+	supported := framework.Capabilities{"a", "b", "typo"}
+	results := Run(TestConfiguration{Capabilities: supported}, func(ldt *T) {
+		ldt.Run("test1", func(ldt1 *T) {
+			ldt1.RequireCapability("a")
+		})
+		ldt.Run("test2", func(ldt1 *T) {
+			ldt1.RequireCapabilities("a", "c")
+		})
+		ldt.Run("test3", func(ldt1 *T) {
+			ldt1.RequireCapability("a")
+			ldt1.Errorf("failed")
+		})
+	})
+
+	assert.Equal(t, map[string][]TestID{
+		"a": {{"test1"}, {"test2"}, {"test3"}},
+		"c": {{"test2"}},
+	}, results.CapabilityChecks)
+
+	var buf bytes.Buffer
+	WriteCapabilityReport(&buf, results, []string{"a", "b", "c", "d"}, supported)
+	assert.Equal(t, `Capability coverage report
+
+a (supported): 1 passed, 1 failed, 1 skipped
+  [test1] passed
+  [test2] skipped
+  [test3] failed
+c (not supported): 1 skipped
+  [test2] skipped
+
+Capabilities that were not required by any test in this run:
+  b (supported)
+  d
+
+WARNING: the test service reported capabilities that are not used by any test (possible typos):
+  typo
+
+`, buf.String())
+}
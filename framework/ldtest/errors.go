@@ -14,10 +14,10 @@ type ErrorWithStacktrace struct {
 }
 
 type StacktraceInfo struct {
-	FileName string
-	Package  string
-	Function string
-	Line     int
+	FileName string `json:"fileName"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Line     int    `json:"line"`
 }
 
 func (e ErrorWithStacktrace) Error() string { return e.Message }
@@ -84,6 +84,9 @@ StackLoop:
 			break // ldtest.Run is always the root of the test run, no need to go further
 		}
 		if !includeLDTestCode && packageName == currentPackage {
+			if functionName == testGoroutineFunctionName {
+				break // each test function runs on its own goroutine, started by runWithTimeout
+			}
 			continue StackLoop
 		}
 		for _, helperFn := range helperFns {
@@ -93,6 +96,9 @@ StackLoop:
 		}
 
 		callers = append(callers, StacktraceInfo{FileName: file, Package: packageName, Function: functionName, Line: line})
+		if packageName == currentPackage && functionName == testGoroutineFunctionName {
+			break
+		}
 	}
 	return callers
 }
@@ -17,7 +17,7 @@ func TestStacktrace(t *testing.T) {
 			assert.Equal(t, currentPackageName(), stack[0].Package)
 			assert.Contains(t, stack[0].Function, "TestStacktrace.")
 			assert.Equal(t, currentPackageName(), stack[1].Package)
-			assert.Equal(t, "(*T).run", stack[1].Function)
+			assert.Equal(t, testGoroutineFunctionName, stack[1].Function)
 		})
 
 		ldt.Run("auto-filtering removes ldtest methods", func(ldt *T) {
@@ -14,6 +14,16 @@ type Filter interface {
 	Match(id TestID) bool
 }
 
+// DetailedFilter is an optional interface for a Filter that can provide more information about how
+// it applies to a test.
+type DetailedFilter interface {
+	Filter
+	// MatchDetails is like Match, but if the test should not run, it also returns an explanation;
+	// and if the test should run but be treated as non-critical (see T.NonCritical), it returns
+	// a non-empty nonCritical explanation.
+	MatchDetails(id TestID) (match bool, skipReason string, nonCritical string)
+}
+
 type SelfDescribingFilter interface {
 	Describe(out io.Writer, supportedCapabilities, importantCapabilities []string) string
 }
@@ -27,11 +37,25 @@ func (f FilterFunc) Match(id TestID) bool {
 type RegexFilters struct {
 	MustMatch    TestIDPatternList
 	MustNotMatch TestIDPatternList
+	Suppressions *Suppressions
 }
 
 func (r RegexFilters) Match(id TestID) bool {
-	return (!r.MustMatch.IsDefined() || r.MustMatch.AnyMatch(id, true)) &&
-		!r.MustNotMatch.AnyMatch(id, false)
+	match, _, _ := r.MatchDetails(id)
+	return match
+}
+
+func (r RegexFilters) MatchDetails(id TestID) (bool, string, string) {
+	if (r.MustMatch.IsDefined() && !r.MustMatch.AnyMatch(id, true)) || r.MustNotMatch.AnyMatch(id, false) {
+		return false, "excluded by filter parameters", ""
+	}
+	if s := r.Suppressions.Match(id); s != nil {
+		if s.Mode == SuppressionModeNonCritical {
+			return true, "", s.Explanation()
+		}
+		return false, s.Explanation(), ""
+	}
+	return true, "", ""
 }
 
 type TestIDPattern []*regexp.Regexp
@@ -117,6 +141,10 @@ func (r RegexFilters) Describe(out io.Writer, supportedCapabilities, allCapabili
 		}
 		helpers.MustFprintln(out)
 	}
+	if r.Suppressions != nil && len(r.Suppressions.entries) != 0 {
+		helpers.MustFprintf(out, "%d suppressions from the suppression file will be applied\n", len(r.Suppressions.entries))
+		helpers.MustFprintln(out)
+	}
 
 	if len(supportedCapabilities) != 0 {
 		supported := make(map[string]bool)
@@ -0,0 +1,174 @@
+package ldtest
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+)
+
+// JSONTestLogger is a TestLogger that writes a stream of newline-delimited JSON records, one for
+// each test event, so that results can be ingested by other tools. Each record is written as soon
+// as the event happens.
+//
+// Every record has an "event" property, which is one of "start", "error", "finish", "skip", or
+// (at the end of the run) "end". Records for a test also have "test", the full path of the test as
+// a slash-delimited string, and "path", the same path as an array of names.
+type JSONTestLogger struct {
+	encoder    *json.Encoder
+	startTimes map[string]time.Time
+	writeErr   error
+	lock       sync.Mutex
+}
+
+// JSONTestRecord is the schema of each record written by JSONTestLogger.
+type JSONTestRecord struct {
+	Event               string             `json:"event"`
+	Time                time.Time          `json:"time"`
+	Test                string             `json:"test,omitempty"`
+	Path                TestID             `json:"path,omitempty"`
+	Status              string             `json:"status,omitempty"`
+	DurationMillis      *float64           `json:"durationMs,omitempty"`
+	NonCritical         bool               `json:"nonCritical,omitempty"`
+	Attempts            int                `json:"attempts,omitempty"`
+	Explanation         string             `json:"explanation,omitempty"`
+	SkipReason          string             `json:"skipReason,omitempty"`
+	Error               *JSONTestError     `json:"error,omitempty"`
+	Errors              []JSONTestError    `json:"errors,omitempty"`
+	DebugOutput         []JSONDebugMessage `json:"debugOutput,omitempty"`
+	Tests               *int               `json:"tests,omitempty"`
+	Failures            *int               `json:"failures,omitempty"`
+	NonCriticalFailures *int               `json:"nonCriticalFailures,omitempty"`
+	Flaky               *int               `json:"flaky,omitempty"`
+	NotRun              *int               `json:"notRun,omitempty"`
+	ServiceLost         string             `json:"serviceLost,omitempty"`
+}
+
+// JSONTestError is the JSON representation of a test failure.
+type JSONTestError struct {
+	Message    string           `json:"message"`
+	Stacktrace []StacktraceInfo `json:"stacktrace,omitempty"`
+}
+
+// JSONDebugMessage is the JSON representation of a line of captured debug output.
+type JSONDebugMessage struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Values of JSONTestRecord.Status in "finish" records.
+const (
+	JSONTestStatusPassed = "passed"
+	JSONTestStatusFailed = "failed"
+	JSONTestStatusFlaky  = "flaky"
+)
+
+// NewJSONTestLogger creates a JSONTestLogger that writes to the specified Writer. The caller is
+// responsible for closing the Writer, if necessary, after EndLog has been called.
+func NewJSONTestLogger(writer io.Writer) *JSONTestLogger {
+	return &JSONTestLogger{
+		encoder:    json.NewEncoder(writer),
+		startTimes: make(map[string]time.Time),
+	}
+}
+
+func (j *JSONTestLogger) TestStarted(id TestID) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	now := time.Now()
+	j.startTimes[id.String()] = now
+	j.write(JSONTestRecord{Event: "start", Time: now, Test: id.String(), Path: id})
+}
+
+func (j *JSONTestLogger) TestError(id TestID, err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	e := makeJSONTestError(err)
+	j.write(JSONTestRecord{Event: "error", Time: time.Now(), Test: id.String(), Path: id, Error: &e})
+}
+
+func (j *JSONTestLogger) TestFinished(id TestID, result TestResult, debugOutput framework.CapturedOutput) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	now := time.Now()
+	duration := result.Duration
+	if duration <= 0 {
+		duration = now.Sub(j.startTimes[id.String()])
+	}
+	delete(j.startTimes, id.String())
+	durationMillis := float64(duration) / float64(time.Millisecond)
+	record := JSONTestRecord{
+		Event:          "finish",
+		Time:           now,
+		Test:           id.String(),
+		Path:           id,
+		Status:         JSONTestStatusPassed,
+		DurationMillis: &durationMillis,
+		NonCritical:    result.NonCritical,
+		Explanation:    result.Explanation,
+	}
+	if len(result.Errors) != 0 {
+		record.Status = JSONTestStatusFailed
+		for _, err := range result.Errors {
+			record.Errors = append(record.Errors, makeJSONTestError(err))
+		}
+	} else if result.Flaky {
+		record.Status = JSONTestStatusFlaky
+	}
+	if result.Attempts > 1 {
+		record.Attempts = result.Attempts
+	}
+	for _, m := range debugOutput {
+		record.DebugOutput = append(record.DebugOutput, JSONDebugMessage(m))
+	}
+	j.write(record)
+}
+
+func (j *JSONTestLogger) TestSkipped(id TestID, reason string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	delete(j.startTimes, id.String())
+	j.write(JSONTestRecord{Event: "skip", Time: time.Now(), Test: id.String(), Path: id, SkipReason: reason})
+}
+
+// EndLog writes a final "end" record with the total number of tests and failures. It returns the
+// first error, if any, that happened while writing records.
+func (j *JSONTestLogger) EndLog(results Results) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	tests, failures, nonCriticalFailures := len(results.Tests), len(results.Failures), len(results.NonCriticalFailures)
+	record := JSONTestRecord{
+		Event:               "end",
+		Time:                time.Now(),
+		Tests:               &tests,
+		Failures:            &failures,
+		NonCriticalFailures: &nonCriticalFailures,
+	}
+	if len(results.Flaky) != 0 {
+		flaky := len(results.Flaky)
+		record.Flaky = &flaky
+	}
+	if results.ServiceLost != nil {
+		notRun := len(results.NotRun)
+		record.NotRun = &notRun
+		record.ServiceLost = results.ServiceLost.Error()
+	}
+	j.write(record)
+	return j.writeErr
+}
+
+func (j *JSONTestLogger) write(record JSONTestRecord) {
+	if j.writeErr != nil {
+		return // don't keep trying to write after a failure
+	}
+	j.writeErr = j.encoder.Encode(record)
+}
+
+func makeJSONTestError(err error) JSONTestError {
+	if es, ok := err.(ErrorWithStacktrace); ok {
+		return JSONTestError{Message: es.Message, Stacktrace: es.Stacktrace}
+	}
+	return JSONTestError{Message: err.Error()}
+}
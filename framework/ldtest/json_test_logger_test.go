@@ -0,0 +1,82 @@
+package ldtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONTestLogger(&buf)
+	results := Run(TestConfiguration{TestLogger: logger}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Run("passes", func(ldt1 *T) {
+				ldt1.DebugLogger().Println("some output")
+			})
+			ldt0.Run("fails", func(ldt1 *T) {
+				ldt1.NonCritical("not required")
+				ldt1.Errorf("bad thing")
+			})
+			ldt0.Run("skipped", func(ldt1 *T) {
+				ldt1.SkipWithReason("not applicable")
+			})
+		})
+	})
+	require.NoError(t, logger.EndLog(results))
+
+	var records []JSONTestRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r JSONTestRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+
+	var summary []string
+	for _, r := range records {
+		summary = append(summary, r.Event+" "+r.Test)
+	}
+	assert.Equal(t, []string{
+		"start parent",
+		"start parent/passes",
+		"finish parent/passes",
+		"start parent/fails",
+		"error parent/fails",
+		"finish parent/fails",
+		"start parent/skipped",
+		"skip parent/skipped",
+		"finish parent",
+		"end ",
+	}, summary)
+
+	passed := records[2]
+	assert.Equal(t, TestID{"parent", "passes"}, passed.Path)
+	assert.Equal(t, JSONTestStatusPassed, passed.Status)
+	assert.NotNil(t, passed.DurationMillis)
+	require.Len(t, passed.DebugOutput, 1)
+	assert.Equal(t, "some output", passed.DebugOutput[0].Message)
+
+	errorRecord := records[4]
+	require.NotNil(t, errorRecord.Error)
+	assert.Equal(t, "bad thing", errorRecord.Error.Message)
+
+	failed := records[5]
+	assert.Equal(t, JSONTestStatusFailed, failed.Status)
+	assert.True(t, failed.NonCritical)
+	assert.Equal(t, "not required", failed.Explanation)
+	require.Len(t, failed.Errors, 1)
+	assert.Equal(t, "bad thing", failed.Errors[0].Message)
+
+	assert.Equal(t, "not applicable", records[7].SkipReason)
+
+	end := records[9]
+	require.NotNil(t, end.Tests)
+	assert.Equal(t, 4, *end.Tests) // includes the parent and the top-level scope, but not skipped tests
+	assert.Equal(t, 0, *end.Failures)
+	assert.Equal(t, 1, *end.NonCriticalFailures)
+}
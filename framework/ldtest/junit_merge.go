@@ -0,0 +1,135 @@
+package ldtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeJUnitFiles combines the JUnit output files of several shards of a test run (see ShardFilter)
+// into a single file, as if all of the tests had run together.
+//
+// A test that appears in more than one file-- as every test does, since each shard reports the tests
+// that belong to other shards as skipped-- is taken from whichever file has the most significant
+// result for it: a failure, then a flaky pass, then a pass, then a skip. A skip because the test
+// belongs to another shard is the least significant of all.
+//
+// The tests are kept in the order they ran in. A test that was not in any of the previous files, such
+// as a subtest of a group that ran in a later shard, goes right after the test that preceded it in its
+// own file.
+func MergeJUnitFiles(outputPath string, inputPaths ...string) error {
+	var suites []*jUnitMergedSuite
+	suitesByName := make(map[string]*jUnitMergedSuite)
+
+	for _, path := range inputPaths {
+		data, err := os.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		var doc jUnitXMLDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: invalid JUnit XML: %w", path, err)
+		}
+		for _, suite := range doc.Suites {
+			merged, ok := suitesByName[suite.Name]
+			if !ok {
+				merged = &jUnitMergedSuite{
+					name:       suite.Name,
+					properties: withoutJUnitShardProperty(suite.Properties),
+					cases:      make(map[string]*jUnitMergedCase),
+				}
+				suitesByName[suite.Name] = merged
+				suites = append(suites, merged)
+			}
+			merged.add(suite.TestCases)
+		}
+	}
+
+	var doc jUnitXMLDocument
+	for _, merged := range suites {
+		doc.Suites = append(doc.Suites, merged.toXML())
+	}
+	return writeJUnitFile(outputPath, doc)
+}
+
+type jUnitMergedSuite struct {
+	name       string
+	properties []jUnitXMLProperty
+	cases      map[string]*jUnitMergedCase
+	first      *jUnitMergedCase
+}
+
+// jUnitMergedCase is a node in a linked list, so that we can insert tests from later files in between
+// the ones we already have.
+type jUnitMergedCase struct {
+	testCase jUnitXMLTestCase
+	next     *jUnitMergedCase
+}
+
+func (s *jUnitMergedSuite) add(testCases []jUnitXMLTestCase) {
+	var prev *jUnitMergedCase
+	for _, tc := range testCases {
+		// A test that had a non-critical failure has a suffix on its name, but the placeholder for it
+		// in the files of other shards does not.
+		key := strings.TrimSuffix(tc.Name, jUnitNonCriticalTestNameSuffix)
+		c, ok := s.cases[key]
+		if ok {
+			if jUnitResultRank(tc) > jUnitResultRank(c.testCase) {
+				c.testCase = tc
+			}
+		} else {
+			c = &jUnitMergedCase{testCase: tc}
+			s.cases[key] = c
+			if prev == nil {
+				c.next, s.first = s.first, c
+			} else {
+				c.next, prev.next = prev.next, c
+			}
+		}
+		prev = c
+	}
+}
+
+func (s *jUnitMergedSuite) toXML() jUnitXMLTestSuite {
+	suite := jUnitXMLTestSuite{Name: s.name, Properties: s.properties}
+	totalDuration := time.Duration(0)
+	for c := s.first; c != nil; c = c.next {
+		suite.TestCases = append(suite.TestCases, c.testCase)
+		suite.Tests++
+		if c.testCase.Failure != nil {
+			suite.Failures++
+		}
+		seconds, _ := strconv.ParseFloat(c.testCase.Time, 64)
+		totalDuration += time.Duration(seconds * float64(time.Second))
+	}
+	suite.Time = jUnitDurationString(totalDuration)
+	return suite
+}
+
+func jUnitResultRank(tc jUnitXMLTestCase) int {
+	switch {
+	case tc.Failure != nil:
+		return 4
+	case len(tc.FlakyFailures) != 0:
+		return 3
+	case tc.SkipMessage == nil:
+		return 2
+	case !strings.HasPrefix(tc.SkipMessage.Message, shardSkipReasonPrefix):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func withoutJUnitShardProperty(properties []jUnitXMLProperty) []jUnitXMLProperty {
+	var ret []jUnitXMLProperty
+	for _, p := range properties {
+		if p.Name != jUnitShardProperty {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
@@ -14,10 +14,15 @@ import (
 	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
 )
 
+const jUnitNonCriticalTestNameSuffix = " (non-critical)"
+
+const jUnitShardProperty = "tests.shard"
+
 type JUnitTestLogger struct {
 	filePath    string
 	serviceInfo serviceinfo.TestServiceInfo
 	filters     RegexFilters
+	shard       Shard
 	testIDs     []TestID // this slice preserves the order that the tests were run in
 	tests       map[string]jUnitTestStatus
 	lock        sync.Mutex
@@ -25,6 +30,7 @@ type JUnitTestLogger struct {
 
 type jUnitTestStatus struct {
 	failures    []error
+	flaky       bool
 	skipped     o.Maybe[string]
 	nonCritical bool
 	output      string
@@ -56,6 +62,10 @@ type jUnitXMLTestCase struct {
 	Time        string               `xml:"time,attr"`
 	SkipMessage *jUnitXMLSkipMessage `xml:"skipped,omitempty"`
 	Failure     *jUnitXMLFailure     `xml:"failure,omitempty"`
+
+	// FlakyFailures is the Maven Surefire extension for a test that passed after being retried,
+	// which is understood by many CI systems.
+	FlakyFailures []jUnitXMLFailure `xml:"flakyFailure,omitempty"`
 }
 
 type jUnitXMLSkipMessage struct {
@@ -86,6 +96,12 @@ func NewJUnitTestLogger(
 	}
 }
 
+// SetShard records in the output file that this test run was only one shard of the full test suite.
+// The output files of all the shards can then be combined with MergeJUnitFiles.
+func (j *JUnitTestLogger) SetShard(shard Shard) {
+	j.shard = shard
+}
+
 func (j *JUnitTestLogger) TestStarted(id TestID) {
 	j.lock.Lock()
 	defer j.lock.Unlock()
@@ -108,8 +124,15 @@ func (j *JUnitTestLogger) TestFinished(id TestID, result TestResult, debugOutput
 	defer j.lock.Unlock()
 	status := j.tests[id.String()]
 	status.output = debugOutput.ToString("")
-	status.duration = time.Since(status.startTime)
+	if result.Duration > 0 {
+		// This is more accurate than startTime if the test ran in parallel, since the TestLogger is
+		// not notified about parallel tests until they are done.
+		status.duration = result.Duration
+	} else {
+		status.duration = time.Since(status.startTime)
+	}
 	status.nonCritical = result.NonCritical
+	status.flaky = result.Flaky
 	j.tests[id.String()] = status
 }
 
@@ -140,6 +163,9 @@ func (j *JUnitTestLogger) EndLog(results Results) error {
 			Value: j.filters.MustNotMatch.String(),
 		},
 	}
+	if j.shard.Count > 1 {
+		properties = append(properties, jUnitXMLProperty{Name: jUnitShardProperty, Value: j.shard.String()})
+	}
 
 	for _, topLevelID := range getTopLevelIDs(j.testIDs) {
 		suite := jUnitXMLTestSuite{
@@ -154,7 +180,7 @@ func (j *JUnitTestLogger) EndLog(results Results) error {
 			status := j.tests[testID.String()]
 
 			suite.Tests++
-			if len(status.failures) != 0 {
+			if len(status.failures) != 0 && !status.flaky {
 				suite.Failures++
 			}
 			suiteTotalDuration += status.duration
@@ -164,22 +190,21 @@ func (j *JUnitTestLogger) EndLog(results Results) error {
 				Time: jUnitDurationString(status.duration),
 			}
 			if status.nonCritical {
-				testCase.Name += " (non-critical)"
+				testCase.Name += jUnitNonCriticalTestNameSuffix
 			}
 			if status.skipped.IsDefined() {
 				testCase.SkipMessage = &jUnitXMLSkipMessage{Message: status.skipped.Value()}
 			}
-			if len(status.failures) != 0 {
+			if status.flaky {
+				// The failures were all from attempts that were followed by a successful retry.
+				for _, e := range status.failures {
+					testCase.FlakyFailures = append(testCase.FlakyFailures,
+						jUnitXMLFailure{Message: jUnitErrorMessage(e), Contents: status.output})
+				}
+			} else if len(status.failures) != 0 {
 				var messages []string
 				for _, e := range status.failures {
-					message := e.Error()
-					if es, ok := e.(ErrorWithStacktrace); ok {
-						message += "\n  Stacktrace:"
-						for _, s := range es.Stacktrace {
-							message += "\n    " + s.String()
-						}
-					}
-					messages = append(messages, message)
+					messages = append(messages, jUnitErrorMessage(e))
 				}
 				testCase.Failure = &jUnitXMLFailure{
 					Message:  strings.Join(messages, "\n"),
@@ -193,13 +218,28 @@ func (j *JUnitTestLogger) EndLog(results Results) error {
 		doc.Suites = append(doc.Suites, suite)
 	}
 
+	return writeJUnitFile(j.filePath, doc)
+}
+
+func writeJUnitFile(path string, doc jUnitXMLDocument) error {
 	bytes, err := xml.MarshalIndent(doc, "", "  ")
 	if err != nil {
 		return err
 	}
 	bytes = append(bytes, '\n')
 
-	return os.WriteFile(j.filePath, bytes, 0644) //nolint:gosec
+	return os.WriteFile(path, bytes, 0644) //nolint:gosec
+}
+
+func jUnitErrorMessage(e error) string {
+	message := e.Error()
+	if es, ok := e.(ErrorWithStacktrace); ok {
+		message += "\n  Stacktrace:"
+		for _, s := range es.Stacktrace {
+			message += "\n    " + s.String()
+		}
+	}
+	return message
 }
 
 func getTopLevelIDs(allIDs []TestID) []string {
@@ -0,0 +1,71 @@
+package ldtest
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+)
+
+// ListTestLogger is a TestLogger for a dry run of the test suite, where there is no test service. At
+// the end of the run, it prints the tree of all tests that were found, in the order they started,
+// with the reason for each test that was skipped.
+//
+// This is meant to be used with TestConfiguration.DryRun, so that tests keep going after a failed
+// assertion and can start the rest of their subtests. A test that stops before the end anyway, for
+// instance because it panicked on an unexpected empty value from a test service that doesn't
+// really exist, is shown with a note that any subtests it would have started after that point could
+// not be listed.
+type ListTestLogger struct {
+	out   io.Writer
+	order []TestID
+	notes map[string]string
+	lock  sync.Mutex
+}
+
+// NewListTestLogger creates a ListTestLogger that writes to the specified Writer.
+func NewListTestLogger(out io.Writer) *ListTestLogger {
+	return &ListTestLogger{out: out, notes: make(map[string]string)}
+}
+
+func (l *ListTestLogger) TestStarted(id TestID) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.order = append(l.order, id)
+}
+
+func (l *ListTestLogger) TestError(TestID, error) {}
+
+func (l *ListTestLogger) TestFinished(id TestID, result TestResult, _ framework.CapturedOutput) {
+	if result.Incomplete {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		l.notes[id.String()] = "stopped before the end, so subtests after that point (if any) are not listed"
+	}
+}
+
+func (l *ListTestLogger) TestSkipped(id TestID, reason string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.notes[id.String()] = "SKIPPED: " + reason
+}
+
+func (l *ListTestLogger) EndLog(Results) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	total, skipped := 0, 0
+	for _, id := range l.order {
+		note := l.notes[id.String()]
+		total++
+		if strings.HasPrefix(note, "SKIPPED") {
+			skipped++
+		}
+		helpers.MustFprintf(l.out, "%s[%s]%s\n", strings.Repeat("  ", len(id)-1), id,
+			helpers.IfElse(note == "", "", " - "+note))
+	}
+	helpers.MustFprintln(l.out)
+	helpers.MustFprintf(l.out, "%d tests listed, %d skipped\n", total, skipped)
+	return nil
+}
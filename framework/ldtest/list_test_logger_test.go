@@ -0,0 +1,45 @@
+package ldtest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewListTestLogger(&buf)
+	var filters RegexFilters
+	require.NoError(t, filters.MustNotMatch.Set("parent/filtered"))
+	results := Run(TestConfiguration{TestLogger: logger, Filter: filters, DryRun: true}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Run("runs", func(ldt1 *T) {})
+			ldt0.Run("filtered", func(ldt1 *T) {})
+			ldt0.Run("needs capability", func(ldt1 *T) {
+				ldt1.RequireCapability("x")
+			})
+			ldt0.Run("assertion fails", func(ldt1 *T) {
+				require.NoError(ldt1, errors.New("no service"))
+				ldt1.Run("still listed", func(ldt2 *T) {})
+			})
+			ldt0.Run("stops", func(ldt1 *T) {
+				var m map[string]*T
+				m["x"].Run("unreachable", func(ldt2 *T) {})
+			})
+		})
+	})
+	require.NoError(t, logger.EndLog(results))
+	assert.Equal(t, `[parent]
+  [parent/runs]
+  [parent/filtered] - SKIPPED: excluded by filter parameters
+  [parent/needs capability] - SKIPPED: test service does not have capability "x"
+  [parent/assertion fails]
+    [parent/assertion fails/still listed]
+  [parent/stops] - stopped before the end, so subtests after that point (if any) are not listed
+
+7 tests listed, 2 skipped
+`, buf.String())
+}
@@ -0,0 +1,199 @@
+package ldtest
+
+import "sync"
+
+// Parallel signals that this test can run in parallel with other subtests of the same parent test
+// that have also called Parallel. It is equivalent to Go's testing.T.Parallel: the test pauses until
+// the parent test's own function has returned, and then runs at the same time as its parallel
+// siblings, up to the limit set by TestConfiguration.MaxParallel. The parent test does not finish,
+// and its Defer functions are not called, until all of its parallel subtests have finished.
+//
+// Parallel should be called at the start of the test function, before it does anything that could
+// interfere with tests that are running serially in the meantime.
+//
+// Output for parallel tests is reported to the TestLogger in the order that the tests were started,
+// regardless of the order that they finish in, so that the console and JUnit output are the same
+// as for a sequential run. For the same reason, when parallel execution is enabled, the TestLogger
+// is not told that a test has started until the test first produces some output or finishes.
+//
+// If MaxParallel is 0 or 1, Parallel does nothing.
+func (t *T) Parallel() {
+	if !t.env.parallelEnabled() || t.parent == nil || t.isParallel {
+		return
+	}
+	t.isParallel = true
+	t.events.startBuffering()
+
+	// While this test is waiting to run, any further output from the parent belongs to the parent.
+	t.parent.debugLogger.RemoveChildLogger(&t.debugLogger)
+	t.release = make(chan struct{})
+	t.parent.lock.Lock()
+	t.parent.parallelSubtests = append(t.parent.parallelSubtests, t)
+	t.parent.lock.Unlock()
+	t.signalStarted()
+
+	t.watchdog.pause() // time spent waiting to run doesn't count toward the test's time limit
+	<-t.release
+	t.env.acquireWorker()
+	t.watchdog.resume()
+	t.parent.debugLogger.ReattachChildLogger(&t.debugLogger)
+}
+
+func (e *environment) parallelEnabled() bool {
+	return e.workers != nil
+}
+
+// Worker slots are counted the same way as in Go's testing package: a slot belongs to whatever line
+// of execution is currently running a test function, so a serial subtest runs in its parent's slot,
+// and a test that is waiting for its parallel subtests gives up its slot while it waits.
+func (e *environment) acquireWorker() {
+	e.workers <- struct{}{}
+}
+
+func (e *environment) releaseWorker() {
+	<-e.workers
+}
+
+// emitForSubtest delivers the events for a subtest that is not going to run, such as one that was
+// excluded by the filter. If any parallel subtests that were started before it are still waiting to
+// run, the events are queued after theirs, so that the output is still in the order that the tests
+// were started.
+func (t *T) emitForSubtest(fn func()) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.parallelSubtests) == 0 {
+		t.events.emit(fn)
+		return
+	}
+	// The placeholder only needs the fields that finishParallelSubtests uses.
+	placeholder := &T{
+		events:  newEventQueue(t.events, false),
+		release: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	placeholder.events.startBuffering()
+	placeholder.events.emit(fn)
+	close(placeholder.done)
+	t.parallelSubtests = append(t.parallelSubtests, placeholder)
+}
+
+func (t *T) signalStarted() {
+	t.startedOnce.Do(func() { close(t.started) })
+}
+
+// finishParallelSubtests is called when the test function has returned. It lets any parallel
+// subtests run, waits for them to finish, and then reports their output in the order they were
+// started.
+func (t *T) finishParallelSubtests() {
+	if !t.env.parallelEnabled() {
+		return
+	}
+	t.lock.Lock()
+	subtests := t.parallelSubtests
+	t.parallelSubtests = nil
+	t.lock.Unlock()
+
+	if len(subtests) == 0 {
+		if t.isParallel {
+			t.env.releaseWorker()
+		}
+		return
+	}
+	t.env.releaseWorker()
+	for _, s := range subtests {
+		close(s.release)
+	}
+	for _, s := range subtests {
+		<-s.done
+		s.events.flush()
+	}
+	if !t.isParallel {
+		t.env.acquireWorker()
+	}
+}
+
+type eventQueueState int
+
+const (
+	// eventsPassThrough means events are forwarded immediately.
+	eventsPassThrough eventQueueState = iota
+	// eventsHeld means we don't yet know whether the test is parallel, so events are queued until
+	// either the test calls Parallel or it produces some other output.
+	eventsHeld
+	// eventsBuffered means the test is parallel, so events are queued until the parent test
+	// flushes them.
+	eventsBuffered
+)
+
+// eventQueue delivers a test's TestLogger calls and result updates to its parent test's queue, or,
+// for the top-level test, executes them. This is how the output of parallel tests is kept in order.
+type eventQueue struct {
+	target  *eventQueue
+	state   eventQueueState
+	pending []func()
+	lock    sync.Mutex
+}
+
+func newEventQueue(target *eventQueue, held bool) *eventQueue {
+	q := &eventQueue{target: target}
+	if held {
+		q.state = eventsHeld
+	}
+	return q
+}
+
+// hold queues an event without ending the eventsHeld state. It is used for the TestStarted event.
+func (q *eventQueue) hold(fn func()) {
+	q.lock.Lock()
+	if q.state == eventsHeld {
+		q.pending = append(q.pending, fn)
+		q.lock.Unlock()
+		return
+	}
+	q.lock.Unlock()
+	q.emit(fn)
+}
+
+func (q *eventQueue) emit(fn func()) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	switch q.state {
+	case eventsBuffered:
+		q.pending = append(q.pending, fn)
+		return
+	case eventsHeld:
+		q.forwardPending()
+		q.state = eventsPassThrough
+	}
+	q.forward(fn)
+}
+
+func (q *eventQueue) startBuffering() {
+	q.lock.Lock()
+	q.state = eventsBuffered
+	q.lock.Unlock()
+}
+
+func (q *eventQueue) flush() {
+	q.lock.Lock()
+	q.forwardPending()
+	q.state = eventsPassThrough
+	q.lock.Unlock()
+}
+
+// forwardPending and forward must be called while holding the lock, so that events from different
+// goroutines cannot be reordered on the way to the target.
+func (q *eventQueue) forwardPending() {
+	for _, fn := range q.pending {
+		q.forward(fn)
+	}
+	q.pending = nil
+}
+
+func (q *eventQueue) forward(fn func()) {
+	if q.target == nil {
+		fn()
+	} else {
+		q.target.emit(fn)
+	}
+}
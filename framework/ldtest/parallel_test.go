@@ -0,0 +1,193 @@
+package ldtest
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTestLogger struct {
+	events []string
+	lock   sync.Mutex
+}
+
+func (r *recordingTestLogger) record(format string, args ...interface{}) {
+	r.lock.Lock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+	r.lock.Unlock()
+}
+
+func (r *recordingTestLogger) TestStarted(id TestID)          { r.record("started %s", id) }
+func (r *recordingTestLogger) TestError(id TestID, err error) { r.record("error %s: %s", id, err) }
+func (r *recordingTestLogger) TestSkipped(id TestID, reason string) {
+	r.record("skipped %s", id)
+}
+func (r *recordingTestLogger) TestFinished(id TestID, result TestResult, output framework.CapturedOutput) {
+	var lines []string
+	for _, m := range output {
+		lines = append(lines, m.Message)
+	}
+	r.record("finished %s %v", id, lines)
+}
+func (r *recordingTestLogger) EndLog(Results) error { return nil }
+
+func TestParallelTestsRunConcurrently(t *testing.T) {
+	var running, maxRunning int32
+	startTime := time.Now()
+	_ = Run(TestConfiguration{MaxParallel: 3}, func(ldt *T) {
+		for i := 0; i < 6; i++ {
+			ldt.Run(fmt.Sprintf("test%d", i), func(ldt1 *T) {
+				ldt1.Parallel()
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond * 100)
+				atomic.AddInt32(&running, -1)
+			})
+		}
+	})
+	assert.Equal(t, int32(3), maxRunning)
+	assert.Less(t, time.Since(startTime), time.Millisecond*500)
+}
+
+func TestParallelTestsStartAfterParentFunctionReturns(t *testing.T) {
+	var order []string
+	var lock sync.Mutex
+	add := func(s string) {
+		lock.Lock()
+		order = append(order, s)
+		lock.Unlock()
+	}
+	_ = Run(TestConfiguration{MaxParallel: 2}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Defer(func() { add("parent cleanup") })
+			ldt0.Run("parallel", func(ldt1 *T) {
+				ldt1.Parallel()
+				add("parallel")
+			})
+			ldt0.Run("serial", func(ldt2 *T) {
+				add("serial")
+			})
+			add("parent end")
+		})
+	})
+	assert.Equal(t, []string{"serial", "parent end", "parallel", "parent cleanup"}, order)
+}
+
+func TestParallelTestOutputIsReportedInOrder(t *testing.T) {
+	logger := &recordingTestLogger{}
+	result := Run(TestConfiguration{MaxParallel: 4, TestLogger: logger}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.DebugLogger().Println("parent log")
+			for i, delay := range []time.Duration{30, 20, 10} {
+				ldt0.Run(fmt.Sprintf("test%d", i), func(ldt1 *T) {
+					ldt1.Parallel()
+					time.Sleep(time.Millisecond * delay)
+					ldt1.DebugLogger().Printf("log %d", i)
+					if i == 1 {
+						ldt1.Errorf("failure %d", i)
+					}
+				})
+			}
+		})
+	})
+
+	assert.Equal(t, []string{
+		"started parent",
+		"started parent/test0",
+		"finished parent/test0 [parent log log 0]",
+		"started parent/test1",
+		"error parent/test1: failure 1",
+		"finished parent/test1 [parent log log 1]",
+		"started parent/test2",
+		"finished parent/test2 [parent log log 2]",
+		"finished parent [parent log]",
+	}, logger.events)
+
+	require.Len(t, result.Tests, 5)
+	assert.Equal(t, TestID{"parent", "test0"}, result.Tests[0].TestID)
+	assert.Equal(t, TestID{"parent", "test1"}, result.Tests[1].TestID)
+	assert.Equal(t, TestID{"parent", "test2"}, result.Tests[2].TestID)
+	assert.Equal(t, TestID{"parent"}, result.Tests[3].TestID)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, TestID{"parent", "test1"}, result.Failures[0].TestID)
+}
+
+func TestSkippedTestIsReportedInOrderAfterParallelTests(t *testing.T) {
+	logger := &recordingTestLogger{}
+	var filters RegexFilters
+	require.NoError(t, filters.MustNotMatch.Set("parent/filtered"))
+	_ = Run(TestConfiguration{MaxParallel: 4, TestLogger: logger, Filter: filters}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Run("test0", func(ldt1 *T) { ldt1.Parallel() })
+			ldt0.Run("filtered", func(ldt1 *T) {})
+			ldt0.Run("test1", func(ldt1 *T) { ldt1.Parallel() })
+		})
+	})
+
+	assert.Equal(t, []string{
+		"started parent",
+		"started parent/test0",
+		"finished parent/test0 []",
+		"started parent/filtered",
+		"skipped parent/filtered",
+		"started parent/test1",
+		"finished parent/test1 []",
+		"finished parent []",
+	}, logger.events)
+}
+
+func TestParallelDoesNothingIfMaxParallelIsNotSet(t *testing.T) {
+	var order []string
+	_ = Run(TestConfiguration{}, func(ldt *T) {
+		ldt.Run("test1", func(ldt1 *T) {
+			ldt1.Parallel()
+			order = append(order, "test1")
+		})
+		order = append(order, "after test1")
+	})
+	assert.Equal(t, []string{"test1", "after test1"}, order)
+}
+
+func TestDeferredFunctionsAreCalledAfterSkip(t *testing.T) {
+	for _, maxParallel := range []int{1, 2} {
+		t.Run(fmt.Sprintf("MaxParallel=%d", maxParallel), func(t *testing.T) {
+			called := false
+			_ = Run(TestConfiguration{MaxParallel: maxParallel}, func(ldt *T) {
+				ldt.Run("test1", func(ldt1 *T) {
+					ldt1.Parallel()
+					ldt1.Defer(func() { called = true })
+					ldt1.Skip()
+				})
+			})
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestSetContextAffectsOnlyCurrentScopeAndSubtests(t *testing.T) {
+	_ = Run(TestConfiguration{Context: "original", MaxParallel: 2}, func(ldt *T) {
+		ldt.Run("test1", func(ldt1 *T) {
+			ldt1.Parallel()
+			ldt1.SetContext("modified")
+			ldt1.Run("subtest", func(ldt2 *T) {
+				assert.Equal(t, "modified", ldt2.Context())
+			})
+		})
+		ldt.Run("test2", func(ldt1 *T) {
+			assert.Equal(t, "original", ldt1.Context())
+		})
+		assert.Equal(t, "original", ldt.Context())
+	})
+}
@@ -3,12 +3,27 @@ package ldtest
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Results struct {
 	Tests               []TestResult
 	Failures            []TestResult
 	NonCriticalFailures []TestResult
+
+	// Flaky contains tests that failed at first but then passed when they were retried, if
+	// TestConfiguration.RetryFailures was set. They are not included in Failures.
+	Flaky []TestResult
+
+	// ServiceLost is non-nil if the test run could not continue, because TestConfiguration.CheckService
+	// reported that the test service was no longer available. NotRun contains the IDs of the tests
+	// that would have been run after that point, not including their subtests.
+	ServiceLost error
+	NotRun      []TestID
+
+	// CapabilityChecks maps each capability name that was passed to T.RequireCapability or
+	// T.RequireCapabilities to the IDs of the tests that required it, in the order they ran.
+	CapabilityChecks map[string][]TestID
 }
 
 type TestResult struct {
@@ -16,10 +31,22 @@ type TestResult struct {
 	Errors      []error
 	NonCritical bool
 	Explanation string
+	Duration    time.Duration
+
+	// Incomplete is true if the test function did not run to the end, because it called FailNow,
+	// panicked, or timed out.
+	Incomplete bool
+
+	// Attempts is the number of times the test was run; it is more than 1 only if the test was
+	// retried after failing. RetriedErrors contains the errors from every attempt but the last, and
+	// Flaky is true if the last attempt passed.
+	Attempts      int
+	RetriedErrors []error
+	Flaky         bool
 }
 
 func (r Results) OK() bool {
-	return len(r.Failures) == 0
+	return len(r.Failures) == 0 && r.ServiceLost == nil
 }
 
 type TestID []string
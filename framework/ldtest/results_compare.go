@@ -0,0 +1,147 @@
+package ldtest
+
+import (
+	"fmt"
+	"io"
+)
+
+// ResultsComparison describes the differences between two test runs, as computed by CompareResults.
+// Each list is in the order that the tests appeared in the newer results, or for Disappeared, in the
+// older results.
+type ResultsComparison struct {
+	// NewlyFailing contains tests that failed in the new run, but passed or were skipped in the old
+	// run. This includes non-critical failures.
+	NewlyFailing []TestResultChange
+	// NewlyPassing contains tests that passed in the new run, but failed in the old run. This includes
+	// tests that were flaky in the new run, since they did pass when retried.
+	NewlyPassing []TestResultChange
+	// Appeared contains tests that exist in the new run but not in the old run.
+	Appeared []TestResultChange
+	// Disappeared contains tests that exist in the old run but not in the new run.
+	Disappeared []TestResultChange
+	// SkipChanged contains tests that were skipped in one run but not the other, or were skipped
+	// in both runs for different reasons, and are not in any of the other lists.
+	SkipChanged []TestResultChange
+	// FlakyChanged contains tests that were flaky in one run but not the other, and are not in any of
+	// the other lists.
+	FlakyChanged []TestResultChange
+}
+
+// TestResultChange describes the outcome of a test in two runs. If the test did not exist in one
+// of the runs, the corresponding field is nil.
+type TestResultChange struct {
+	ID     string
+	Before *RecordedTestResult
+	After  *RecordedTestResult
+}
+
+// CompareResults computes the differences between two test runs.
+func CompareResults(before, after RecordedResults) ResultsComparison {
+	var c ResultsComparison
+	for _, id := range after.Order {
+		a := after.Tests[id]
+		b, existed := before.Tests[id]
+		if !existed {
+			c.Appeared = append(c.Appeared, TestResultChange{ID: id, After: &a})
+			continue
+		}
+		change := TestResultChange{ID: id, Before: &b, After: &a}
+		switch {
+		case a.failed() && !b.failed():
+			c.NewlyFailing = append(c.NewlyFailing, change)
+		case b.failed() && (a.Status == RecordedTestPassed || a.Status == RecordedTestFlaky):
+			c.NewlyPassing = append(c.NewlyPassing, change)
+		case (a.Status == RecordedTestSkipped) != (b.Status == RecordedTestSkipped),
+			a.Status == RecordedTestSkipped && a.SkipReason != b.SkipReason:
+			c.SkipChanged = append(c.SkipChanged, change)
+		case (a.Status == RecordedTestFlaky) != (b.Status == RecordedTestFlaky):
+			c.FlakyChanged = append(c.FlakyChanged, change)
+		}
+	}
+	for _, id := range before.Order {
+		if _, exists := after.Tests[id]; !exists {
+			b := before.Tests[id]
+			c.Disappeared = append(c.Disappeared, TestResultChange{ID: id, Before: &b})
+		}
+	}
+	return c
+}
+
+// Regressions returns the tests that have a critical failure in the new run but did not fail in
+// the old run, including tests that did not exist in the old run. Non-critical failures, and tests
+// that disappeared, are not counted.
+func (c ResultsComparison) Regressions() []TestResultChange {
+	var ret []TestResultChange
+	for _, list := range [][]TestResultChange{c.NewlyFailing, c.Appeared} {
+		for _, change := range list {
+			if change.After.Status == RecordedTestFailed {
+				ret = append(ret, change)
+			}
+		}
+	}
+	return ret
+}
+
+// HasChanges returns true if there are any differences between the runs.
+func (c ResultsComparison) HasChanges() bool {
+	return len(c.NewlyFailing)+len(c.NewlyPassing)+len(c.Appeared)+len(c.Disappeared)+len(c.SkipChanged)+
+		len(c.FlakyChanged) != 0
+}
+
+// Write prints a human-readable report of the differences.
+func (c ResultsComparison) Write(w io.Writer) error {
+	sections := []struct {
+		title   string
+		changes []TestResultChange
+	}{
+		{"Newly failing", c.NewlyFailing},
+		{"Newly passing", c.NewlyPassing},
+		{"Appeared", c.Appeared},
+		{"Disappeared", c.Disappeared},
+		{"Skip status changed", c.SkipChanged},
+		{"Flaky status changed", c.FlakyChanged},
+	}
+	for _, s := range sections {
+		if len(s.changes) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s (%d):\n", s.title, len(s.changes)); err != nil {
+			return err
+		}
+		for _, change := range s.changes {
+			if _, err := fmt.Fprintf(w, "  [%s] %s -> %s\n", change.ID,
+				change.Before.describe(), change.After.describe()); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	regressions := c.Regressions()
+	var err error
+	switch {
+	case len(regressions) != 0:
+		_, err = fmt.Fprintf(w, "%d regression(s)\n", len(regressions))
+	case c.HasChanges():
+		_, err = fmt.Fprintln(w, "No regressions")
+	default:
+		_, err = fmt.Fprintln(w, "No differences")
+	}
+	return err
+}
+
+func (r RecordedTestResult) failed() bool {
+	return r.Status == RecordedTestFailed || r.Status == RecordedTestFailedNonCritical
+}
+
+func (r *RecordedTestResult) describe() string {
+	switch {
+	case r == nil:
+		return "(absent)"
+	case r.Status == RecordedTestSkipped && r.SkipReason != "":
+		return fmt.Sprintf("%s (%s)", r.Status, r.SkipReason)
+	default:
+		return string(r.Status)
+	}
+}
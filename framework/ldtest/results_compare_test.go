@@ -0,0 +1,149 @@
+package ldtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/serviceinfo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runForResultsFile(t *testing.T, format string, outcomes map[string]string) string {
+	path := filepath.Join(t.TempDir(), "results."+format)
+	var logger TestLogger
+	var jsonOut bytes.Buffer
+	if format == "xml" {
+		logger = NewJUnitTestLogger(path, serviceinfo.TestServiceInfo{}, RegexFilters{})
+	} else {
+		logger = NewJSONTestLogger(&jsonOut)
+	}
+	results := Run(TestConfiguration{TestLogger: logger, RetryFailures: 1}, func(ldt *T) {
+		for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+			outcome, ok := outcomes[name]
+			if !ok {
+				continue
+			}
+			attempts := 0
+			ldt.Run(name, func(ldt1 *T) {
+				attempts++
+				switch {
+				case outcome == "flaky" && attempts == 1:
+					ldt1.Errorf("failed the first time")
+				case outcome == "fail":
+					ldt1.Errorf("failed")
+				case outcome == "noncritical":
+					ldt1.NonCritical("optional")
+					ldt1.Errorf("failed")
+				case strings.HasPrefix(outcome, "skip:"):
+					ldt1.SkipWithReason(strings.TrimPrefix(outcome, "skip:"))
+				}
+			})
+		}
+	})
+	require.NoError(t, logger.EndLog(results))
+	if format != "xml" {
+		require.NoError(t, os.WriteFile(path, jsonOut.Bytes(), 0600))
+	}
+	return path
+}
+
+func TestReadResultsFile(t *testing.T) {
+	outcomes := map[string]string{"a": "pass", "b": "fail", "c": "noncritical", "d": "skip:no capability",
+		"e": "flaky"}
+	for _, format := range []string{"json", "xml"} {
+		t.Run(format, func(t *testing.T) {
+			results, err := ReadResultsFile(runForResultsFile(t, format, outcomes))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b", "c", "d", "e"}, results.Order)
+			assert.Equal(t, map[string]RecordedTestResult{
+				"a": {ID: "a", Status: RecordedTestPassed},
+				"b": {ID: "b", Status: RecordedTestFailed},
+				"c": {ID: "c", Status: RecordedTestFailedNonCritical},
+				"d": {ID: "d", Status: RecordedTestSkipped, SkipReason: "no capability"},
+				"e": {ID: "e", Status: RecordedTestFlaky},
+			}, results.Tests)
+		})
+	}
+}
+
+func TestReadResultsFileWithInvalidData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+	_, err := ReadResultsFile(path)
+	assert.Error(t, err)
+}
+
+func TestCompareResults(t *testing.T) {
+	before, err := ReadResultsFile(runForResultsFile(t, "json", map[string]string{
+		"a": "pass", "b": "fail", "c": "skip:reason 1", "d": "pass", "e": "pass",
+	}))
+	require.NoError(t, err)
+	after, err := ReadResultsFile(runForResultsFile(t, "xml", map[string]string{
+		"a": "fail", "b": "pass", "c": "skip:reason 2", "e": "noncritical", "f": "fail",
+	}))
+	require.NoError(t, err)
+
+	c := CompareResults(before, after)
+	ids := func(changes []TestResultChange) []string {
+		var ret []string
+		for _, change := range changes {
+			ret = append(ret, change.ID)
+		}
+		return ret
+	}
+	assert.Equal(t, []string{"a", "e"}, ids(c.NewlyFailing))
+	assert.Equal(t, []string{"b"}, ids(c.NewlyPassing))
+	assert.Equal(t, []string{"f"}, ids(c.Appeared))
+	assert.Equal(t, []string{"d"}, ids(c.Disappeared))
+	assert.Equal(t, []string{"c"}, ids(c.SkipChanged))
+	assert.Equal(t, []string{"a", "f"}, ids(c.Regressions()))
+
+	var out bytes.Buffer
+	require.NoError(t, c.Write(&out))
+	assert.Contains(t, out.String(), "[a] passed -> failed\n")
+	assert.Contains(t, out.String(), "[c] skipped (reason 1) -> skipped (reason 2)\n")
+	assert.Contains(t, out.String(), "[d] passed -> (absent)\n")
+	assert.Contains(t, out.String(), "2 regression(s)\n")
+}
+
+func TestCompareResultsWithFlakyTests(t *testing.T) {
+	before, err := ReadResultsFile(runForResultsFile(t, "json", map[string]string{
+		"a": "fail", "b": "pass", "c": "flaky", "d": "flaky", "e": "flaky",
+	}))
+	require.NoError(t, err)
+	after, err := ReadResultsFile(runForResultsFile(t, "json", map[string]string{
+		"a": "flaky", "b": "flaky", "c": "pass", "d": "flaky", "e": "fail",
+	}))
+	require.NoError(t, err)
+
+	c := CompareResults(before, after)
+	ids := func(changes []TestResultChange) []string {
+		var ret []string
+		for _, change := range changes {
+			ret = append(ret, change.ID)
+		}
+		return ret
+	}
+	assert.Equal(t, []string{"a"}, ids(c.NewlyPassing))
+	assert.Equal(t, []string{"b", "c"}, ids(c.FlakyChanged))
+	assert.Equal(t, []string{"e"}, ids(c.NewlyFailing))
+	assert.Equal(t, []string{"e"}, ids(c.Regressions()))
+
+	var out bytes.Buffer
+	require.NoError(t, c.Write(&out))
+	assert.Contains(t, out.String(), "Flaky status changed (2):\n  [b] passed -> flaky\n  [c] flaky -> passed\n")
+	assert.Contains(t, out.String(), "[a] failed -> flaky\n")
+}
+
+func TestCompareIdenticalResults(t *testing.T) {
+	results, err := ReadResultsFile(runForResultsFile(t, "json", map[string]string{"a": "pass", "b": "fail"}))
+	require.NoError(t, err)
+	c := CompareResults(results, results)
+	assert.False(t, c.HasChanges())
+	assert.Len(t, c.Regressions(), 0)
+}
@@ -0,0 +1,145 @@
+package ldtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RecordedTestStatus is the outcome of a test as recorded in a results file.
+type RecordedTestStatus string
+
+const (
+	RecordedTestPassed            RecordedTestStatus = "passed"
+	RecordedTestFailed            RecordedTestStatus = "failed"
+	RecordedTestFailedNonCritical RecordedTestStatus = "failed (non-critical)"
+	RecordedTestSkipped           RecordedTestStatus = "skipped"
+	RecordedTestFlaky             RecordedTestStatus = "flaky"
+)
+
+const resultsFileFormatErrorMaxLength = 100
+
+// RecordedTestResult is the outcome of a single test as recorded in a results file.
+type RecordedTestResult struct {
+	ID         string
+	Status     RecordedTestStatus
+	SkipReason string
+}
+
+// RecordedResults is the set of test outcomes read from a results file by ReadResultsFile.
+type RecordedResults struct {
+	// Order contains the test IDs in the order that they appeared in the file.
+	Order []string
+	// Tests is a map of test IDs to results.
+	Tests map[string]RecordedTestResult
+}
+
+// ReadResultsFile reads a results file that was written by either JSONTestLogger or JUnitTestLogger.
+// The format is detected from the file content.
+func ReadResultsFile(path string) (RecordedResults, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return RecordedResults{}, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	var results RecordedResults
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		results, err = parseJUnitResults(trimmed)
+	} else {
+		results, err = parseJSONResults(trimmed)
+	}
+	if err != nil {
+		return RecordedResults{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return results, nil
+}
+
+func (r *RecordedResults) add(result RecordedTestResult) {
+	if r.Tests == nil {
+		r.Tests = make(map[string]RecordedTestResult)
+	}
+	if _, exists := r.Tests[result.ID]; !exists {
+		r.Order = append(r.Order, result.ID)
+	}
+	r.Tests[result.ID] = result
+}
+
+func parseJSONResults(data []byte) (RecordedResults, error) {
+	var results RecordedResults
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 64*1024*1024) // debug output can make some lines very long
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record JSONTestRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return results, fmt.Errorf("invalid JSON results at line %d (%s): %w", lineNum,
+				truncateForError(string(line)), err)
+		}
+		if record.Test == "" {
+			continue // the "end" record, or the top-level scope, which isn't a real test
+		}
+		switch record.Event {
+		case "finish":
+			status := RecordedTestPassed
+			switch record.Status {
+			case JSONTestStatusFailed:
+				status = RecordedTestFailed
+				if record.NonCritical {
+					status = RecordedTestFailedNonCritical
+				}
+			case JSONTestStatusFlaky:
+				status = RecordedTestFlaky
+			}
+			results.add(RecordedTestResult{ID: record.Test, Status: status})
+		case "skip":
+			results.add(RecordedTestResult{ID: record.Test, Status: RecordedTestSkipped, SkipReason: record.SkipReason})
+		}
+	}
+	return results, scanner.Err()
+}
+
+func parseJUnitResults(data []byte) (RecordedResults, error) {
+	var results RecordedResults
+	var doc jUnitXMLDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return results, fmt.Errorf("invalid JUnit XML: %w", err)
+	}
+	for _, suite := range doc.Suites {
+		for _, tc := range suite.TestCases {
+			result := RecordedTestResult{ID: tc.Name, Status: RecordedTestPassed}
+			nonCritical := strings.HasSuffix(tc.Name, jUnitNonCriticalTestNameSuffix)
+			if nonCritical {
+				result.ID = strings.TrimSuffix(tc.Name, jUnitNonCriticalTestNameSuffix)
+			}
+			switch {
+			case tc.SkipMessage != nil:
+				result.Status = RecordedTestSkipped
+				result.SkipReason = tc.SkipMessage.Message
+			case tc.Failure != nil && nonCritical:
+				result.Status = RecordedTestFailedNonCritical
+			case tc.Failure != nil:
+				result.Status = RecordedTestFailed
+			case len(tc.FlakyFailures) != 0:
+				result.Status = RecordedTestFlaky
+			}
+			results.add(result)
+		}
+	}
+	return results, nil
+}
+
+func truncateForError(s string) string {
+	if len(s) > resultsFileFormatErrorMaxLength {
+		return s[:resultsFileFormatErrorMaxLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package ldtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedTestThatPassesOnRetryIsFlaky(t *testing.T) {
+	attempts := 0
+	var contexts []interface{}
+	result := Run(TestConfiguration{RetryFailures: 2, Context: "original"}, func(ldt *T) {
+		ldt.Run("flaky", func(ldt1 *T) {
+			attempts++
+			contexts = append(contexts, ldt1.Context())
+			ldt1.SetContext("changed")
+			if attempts == 1 {
+				ldt1.Errorf("failed the first time")
+			}
+		})
+	})
+
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []interface{}{"original", "original"}, contexts)
+	assert.True(t, result.OK())
+	assert.Len(t, result.Failures, 0)
+	require.Len(t, result.Flaky, 1)
+	flaky := result.Flaky[0]
+	assert.Equal(t, TestID{"flaky"}, flaky.TestID)
+	assert.True(t, flaky.Flaky)
+	assert.Equal(t, 2, flaky.Attempts)
+	assert.Len(t, flaky.Errors, 0)
+	require.Len(t, flaky.RetriedErrors, 1)
+	assert.Contains(t, flaky.RetriedErrors[0].Error(), "failed the first time")
+}
+
+func TestTestThatKeepsFailingIsRetriedUpToLimit(t *testing.T) {
+	attempts := 0
+	result := Run(TestConfiguration{RetryFailures: 2}, func(ldt *T) {
+		ldt.Run("broken", func(ldt1 *T) {
+			attempts++
+			ldt1.Errorf("failed again")
+		})
+	})
+
+	assert.Equal(t, 3, attempts)
+	assert.Len(t, result.Flaky, 0)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, 3, result.Failures[0].Attempts)
+	assert.Len(t, result.Failures[0].RetriedErrors, 2)
+	assert.False(t, result.Failures[0].Flaky)
+}
+
+func TestOnlyCriticalFailuresOfLeafTestsAreRetried(t *testing.T) {
+	parentAttempts, childAttempts, nonCriticalAttempts := 0, 0, 0
+	result := Run(TestConfiguration{RetryFailures: 1}, func(ldt *T) {
+		ldt.Run("parent", func(ldt1 *T) {
+			parentAttempts++
+			ldt1.Run("child", func(ldt2 *T) {
+				childAttempts++
+			})
+			ldt1.Errorf("parent failed after its subtest")
+		})
+		ldt.Run("non-critical", func(ldt1 *T) {
+			nonCriticalAttempts++
+			ldt1.NonCritical("optional")
+			ldt1.Errorf("failed")
+		})
+	})
+
+	assert.Equal(t, 1, parentAttempts)
+	assert.Equal(t, 1, childAttempts)
+	assert.Equal(t, 1, nonCriticalAttempts)
+	assert.Len(t, result.Failures, 1)
+	assert.Len(t, result.NonCriticalFailures, 1)
+}
+
+func TestParallelTestIsRetried(t *testing.T) {
+	attempts := 0
+	result := Run(TestConfiguration{RetryFailures: 1, MaxParallel: 2}, func(ldt *T) {
+		ldt.Run("flaky", func(ldt1 *T) {
+			ldt1.Parallel()
+			attempts++
+			if attempts == 1 {
+				ldt1.Errorf("failed the first time")
+			}
+		})
+		ldt.Run("other", func(ldt1 *T) {
+			ldt1.Parallel()
+		})
+	})
+
+	assert.Equal(t, 2, attempts)
+	assert.True(t, result.OK())
+	assert.Len(t, result.Flaky, 1)
+}
@@ -0,0 +1,85 @@
+package ldtest
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// shardUnitDepth is the depth of test ID that is assigned to a shard as a unit, such as
+// "evaluation/parameterized". Top-level tests like "evaluation" are only groupings of other tests,
+// so they run in every shard, but each group of tests at the next level runs in only one shard.
+const shardUnitDepth = 2
+
+// shardSkipReasonPrefix begins the skip reason for a test that belongs to a different shard.
+const shardSkipReasonPrefix = "runs in shard "
+
+// Shard identifies one part of a test run that has been split up so it can run on several machines.
+// Index is 1-based.
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses a string in the format "index/count", such as "2/4".
+func ParseShard(s string) (Shard, error) {
+	indexStr, countStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, errors.New(`shard must be in the format "index/count"`)
+	}
+	index, err1 := strconv.Atoi(indexStr)
+	count, err2 := strconv.Atoi(countStr)
+	if err1 != nil || err2 != nil || count < 1 || index < 1 || index > count {
+		return Shard{}, fmt.Errorf(`invalid shard %q: index and count must be numbers, with 1 <= index <= count`, s)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// ShardFilter is a Filter that runs only the tests that belong to a particular shard, and that
+// also match another Filter (if any).
+//
+// Each test is assigned to a shard based on a hash of the first two components of its test ID, so
+// the assignment does not depend on which tests exist, or on what order they run in; it only
+// changes if a test is renamed, or if the shard count changes.
+type ShardFilter struct {
+	Filter Filter
+	Shard  Shard
+}
+
+func (f ShardFilter) Match(id TestID) bool {
+	match, _, _ := f.MatchDetails(id)
+	return match
+}
+
+func (f ShardFilter) MatchDetails(id TestID) (bool, string, string) {
+	if f.Shard.Count > 1 && len(id) >= shardUnitDepth {
+		if shard := ShardForTestID(id, f.Shard.Count); shard != f.Shard.Index {
+			return false, fmt.Sprintf("%s%d/%d", shardSkipReasonPrefix, shard, f.Shard.Count), ""
+		}
+	}
+	if df, ok := f.Filter.(DetailedFilter); ok {
+		return df.MatchDetails(id)
+	}
+	if f.Filter != nil && !f.Filter.Match(id) {
+		return false, "excluded by filter parameters", ""
+	}
+	return true, "", ""
+}
+
+// ShardForTestID returns the 1-based index of the shard that the test belongs to, out of count
+// shards. This is meaningless for a top-level test, since those run in every shard.
+func ShardForTestID(id TestID, count int) int {
+	unit := id
+	if len(unit) > shardUnitDepth {
+		unit = unit[:shardUnitDepth]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(unit.String()))
+	return int(h.Sum32()%uint32(count)) + 1 //nolint:gosec // count is always positive
+}
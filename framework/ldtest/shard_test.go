@@ -0,0 +1,169 @@
+package ldtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/serviceinfo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	shard, err := ParseShard("2/4")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 4}, shard)
+	assert.Equal(t, "2/4", shard.String())
+
+	for _, s := range []string{"", "2", "0/4", "5/4", "1/0", "a/b", "1/2/3"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseShard(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestShardFilterRunsEachTestGroupInExactlyOneShard(t *testing.T) {
+	count := 3
+	for i := 0; i < 20; i++ {
+		group := TestID{"top", fmt.Sprintf("group%d", i)}
+		matches := 0
+		for index := 1; index <= count; index++ {
+			f := ShardFilter{Shard: Shard{Index: index, Count: count}}
+			assert.True(t, f.Match(TestID{"top"}), "top-level test should run in every shard")
+			if f.Match(group) {
+				matches++
+				assert.True(t, f.Match(group.Plus("subtest")), "subtest should run in the same shard as its group")
+			} else {
+				match, reason, _ := f.MatchDetails(group.Plus("subtest"))
+				assert.False(t, match)
+				assert.Equal(t, fmt.Sprintf("runs in shard %d/%d", ShardForTestID(group, count), count), reason)
+			}
+		}
+		assert.Equal(t, 1, matches, "test group %s", group)
+	}
+}
+
+func TestShardFilterAppliesOtherFilter(t *testing.T) {
+	var mustNotMatch TestIDPatternList
+	require.NoError(t, mustNotMatch.Set("top/excluded"))
+	f := ShardFilter{Filter: RegexFilters{MustNotMatch: mustNotMatch}, Shard: Shard{Index: 1, Count: 1}}
+	assert.True(t, f.Match(TestID{"top", "included"}))
+	match, reason, _ := f.MatchDetails(TestID{"top", "excluded"})
+	assert.False(t, match)
+	assert.Equal(t, "excluded by filter parameters", reason)
+}
+
+func TestMergeJUnitFiles(t *testing.T) {
+	dir := t.TempDir()
+	count := 2
+	var paths []string
+	for index := 1; index <= count; index++ {
+		path := filepath.Join(dir, fmt.Sprintf("shard%d.xml", index))
+		paths = append(paths, path)
+		logger := NewJUnitTestLogger(path, serviceinfo.TestServiceInfo{}, RegexFilters{})
+		shard := Shard{Index: index, Count: count}
+		logger.SetShard(shard)
+		results := Run(TestConfiguration{TestLogger: logger, Filter: ShardFilter{Shard: shard}}, func(ldt *T) {
+			ldt.Run("top", func(ldt1 *T) {
+				for i := 0; i < 6; i++ {
+					ldt1.Run(fmt.Sprintf("group%d", i), func(ldt2 *T) {
+						ldt2.Run("subtest", func(ldt3 *T) {
+							if i == 0 {
+								ldt3.Errorf("failed")
+							}
+						})
+					})
+				}
+			})
+		})
+		require.NoError(t, logger.EndLog(results))
+	}
+
+	mergedPath := filepath.Join(dir, "merged.xml")
+	require.NoError(t, MergeJUnitFiles(mergedPath, paths...))
+
+	results, err := ReadResultsFile(mergedPath)
+	require.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		expected := RecordedTestPassed
+		if i == 0 {
+			expected = RecordedTestFailed
+		}
+		assert.Equal(t, RecordedTestPassed, results.Tests[fmt.Sprintf("top/group%d", i)].Status)
+		assert.Equal(t, expected, results.Tests[fmt.Sprintf("top/group%d/subtest", i)].Status)
+	}
+	assert.Equal(t, RecordedTestPassed, results.Tests["top"].Status)
+
+	data, err := os.ReadFile(mergedPath)
+	require.NoError(t, err)
+	var doc jUnitXMLDocument
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Len(t, doc.Suites, 1)
+	assert.Equal(t, 13, doc.Suites[0].Tests)
+	var names []string
+	for _, tc := range doc.Suites[0].TestCases {
+		names = append(names, tc.Name)
+	}
+	expectedNames := []string{"top"}
+	for i := 0; i < 6; i++ {
+		expectedNames = append(expectedNames, fmt.Sprintf("top/group%d", i), fmt.Sprintf("top/group%d/subtest", i))
+	}
+	assert.Equal(t, expectedNames, names, "tests should be in the order they ran in")
+	assert.Equal(t, 1, doc.Suites[0].Failures)
+	for _, p := range doc.Suites[0].Properties {
+		assert.NotEqual(t, jUnitShardProperty, p.Name)
+	}
+}
+
+func TestMergeJUnitFilesWithNonCriticalFailures(t *testing.T) {
+	dir := t.TempDir()
+	count := 2
+	var paths []string
+	for index := 1; index <= count; index++ {
+		path := filepath.Join(dir, fmt.Sprintf("shard%d.xml", index))
+		paths = append(paths, path)
+		logger := NewJUnitTestLogger(path, serviceinfo.TestServiceInfo{}, RegexFilters{})
+		shard := Shard{Index: index, Count: count}
+		logger.SetShard(shard)
+		results := Run(TestConfiguration{TestLogger: logger, Filter: ShardFilter{Shard: shard}}, func(ldt *T) {
+			ldt.Run("top", func(ldt1 *T) {
+				for i := 0; i < 6; i++ {
+					ldt1.Run(fmt.Sprintf("group%d", i), func(ldt2 *T) {
+						ldt2.NonCritical("optional")
+						ldt2.Errorf("failed")
+					})
+				}
+			})
+		})
+		require.NoError(t, logger.EndLog(results))
+	}
+
+	mergedPath := filepath.Join(dir, "merged.xml")
+	require.NoError(t, MergeJUnitFiles(mergedPath, paths...))
+
+	data, err := os.ReadFile(mergedPath)
+	require.NoError(t, err)
+	var doc jUnitXMLDocument
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Len(t, doc.Suites, 1)
+	var names []string
+	for _, tc := range doc.Suites[0].TestCases {
+		names = append(names, tc.Name)
+	}
+	expectedNames := []string{"top"}
+	for i := 0; i < 6; i++ {
+		expectedNames = append(expectedNames, fmt.Sprintf("top/group%d", i)+jUnitNonCriticalTestNameSuffix)
+	}
+	assert.Equal(t, expectedNames, names, "each test should appear only once, with its real result")
+
+	results, err := ReadResultsFile(mergedPath)
+	require.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, RecordedTestFailedNonCritical, results.Tests[fmt.Sprintf("top/group%d", i)].Status)
+	}
+}
@@ -0,0 +1,202 @@
+package ldtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionMode determines what happens to a test that matches a Suppression.
+type SuppressionMode string
+
+const (
+	// SuppressionModeSkip means the test is skipped. This is the default.
+	SuppressionModeSkip SuppressionMode = "skip"
+	// SuppressionModeNonCritical means the test runs, but if it fails, the failure is reported as
+	// non-critical as if the test had called T.NonCritical.
+	SuppressionModeNonCritical SuppressionMode = "non-critical"
+)
+
+const suppressionExpiryDateFormat = "2006-01-02"
+
+// Suppression is an entry in a suppression file. It applies to the tests that match either Test
+// or Pattern, and all of their subtests.
+type Suppression struct {
+	// Test is the full path of a test, such as "streaming/retry behavior/retry after IO error".
+	Test string `yaml:"test"`
+	// Pattern is a pattern with the same syntax as the -skip parameter.
+	Pattern string `yaml:"pattern"`
+	// Reason is an explanation of why the test is suppressed.
+	Reason string `yaml:"reason"`
+	// Ticket is an optional link to an issue for fixing the problem.
+	Ticket string `yaml:"ticket"`
+	// Expires is an optional date, in the format "2006-01-02", after which a warning is printed
+	// to say that the suppression should be reviewed. The suppression still applies after that date.
+	Expires string `yaml:"expires"`
+	// Mode is either "skip" (the default) or "non-critical".
+	Mode SuppressionMode `yaml:"mode"`
+
+	pattern TestIDPattern
+	expiry  time.Time
+	matched bool
+}
+
+// Suppressions is a list of suppressions loaded from a file with LoadSuppressionsFile or
+// ParseSuppressions. It is safe for concurrent use.
+type Suppressions struct {
+	entries []*Suppression
+	lock    sync.Mutex
+}
+
+type suppressionsFile struct {
+	Suppressions []*Suppression `yaml:"suppressions"`
+}
+
+// LoadSuppressionsFile reads a suppression file in YAML or JSON format.
+func LoadSuppressionsFile(path string) (*Suppressions, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSuppressions(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseSuppressions parses the content of a suppression file in YAML or JSON format.
+func ParseSuppressions(data []byte) (*Suppressions, error) {
+	var file suppressionsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i, entry := range file.Suppressions {
+		if err := entry.init(); err != nil {
+			return nil, fmt.Errorf("suppression %d: %w", i+1, err)
+		}
+	}
+	return &Suppressions{entries: file.Suppressions}, nil
+}
+
+func (s *Suppression) init() error {
+	var err error
+	switch {
+	case (s.Test == "") == (s.Pattern == ""):
+		return errors.New(`must have exactly one of "test" or "pattern"`)
+	case s.Test != "":
+		for _, name := range strings.Split(s.Test, "/") {
+			s.pattern = append(s.pattern, regexp.MustCompile("^"+regexp.QuoteMeta(name)+"$"))
+		}
+	default:
+		if s.pattern, err = ParseTestIDPattern(s.Pattern); err != nil {
+			return err
+		}
+	}
+	switch s.Mode {
+	case "":
+		s.Mode = SuppressionModeSkip
+	case SuppressionModeSkip, SuppressionModeNonCritical:
+	default:
+		return fmt.Errorf("unknown mode %q", s.Mode)
+	}
+	if s.Expires != "" {
+		if s.expiry, err = time.Parse(suppressionExpiryDateFormat, s.Expires); err != nil {
+			return fmt.Errorf("invalid expiry date %q (must be YYYY-MM-DD)", s.Expires)
+		}
+	}
+	return nil
+}
+
+// String returns the test path or pattern of the suppression.
+func (s *Suppression) String() string {
+	return helpers.IfElse(s.Test != "", s.Test, s.Pattern)
+}
+
+// Explanation returns a description of the suppression that includes the reason and ticket.
+func (s *Suppression) Explanation() string {
+	ret := "suppressed"
+	if s.Reason != "" {
+		ret += ": " + s.Reason
+	}
+	if s.Ticket != "" {
+		ret += " (" + s.Ticket + ")"
+	}
+	return ret
+}
+
+// IsExpired returns true if the suppression has an expiry date that is before the specified time.
+func (s *Suppression) IsExpired(now time.Time) bool {
+	return !s.expiry.IsZero() && now.After(s.expiry.AddDate(0, 0, 1))
+}
+
+// Match returns the first suppression that applies to the specified test, or nil if none does.
+func (s *Suppressions) Match(id TestID) *Suppression {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, entry := range s.entries {
+		if entry.pattern.Match(id, false) {
+			entry.matched = true
+			return entry
+		}
+	}
+	return nil
+}
+
+// Expired returns all suppressions whose expiry date is before the specified time.
+func (s *Suppressions) Expired(now time.Time) []*Suppression {
+	var ret []*Suppression
+	for _, entry := range s.entries {
+		if entry.IsExpired(now) {
+			ret = append(ret, entry)
+		}
+	}
+	return ret
+}
+
+// Unmatched returns all suppressions that have not matched any test so far.
+func (s *Suppressions) Unmatched() []*Suppression {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var ret []*Suppression
+	for _, entry := range s.entries {
+		if !entry.matched {
+			ret = append(ret, entry)
+		}
+	}
+	return ret
+}
+
+// WriteExpiryWarnings prints a warning for each suppression that has expired.
+func (s *Suppressions) WriteExpiryWarnings(out io.Writer, now time.Time) {
+	for _, entry := range s.Expired(now) {
+		helpers.MustFprintf(out, "WARNING: suppression for %q expired on %s; %s\n",
+			entry.String(), entry.Expires, entry.Explanation())
+	}
+}
+
+// WriteUnmatched prints a list of the suppressions that did not match any test.
+func (s *Suppressions) WriteUnmatched(out io.Writer) {
+	unmatched := s.Unmatched()
+	if len(unmatched) == 0 {
+		return
+	}
+	helpers.MustFprintln(out, "These suppressions did not match any test that was considered in this run,"+
+		" and might no longer be needed:")
+	for _, entry := range unmatched {
+		helpers.MustFprintf(out, "  %s\n", entry.String())
+	}
+	helpers.MustFprintln(out)
+}
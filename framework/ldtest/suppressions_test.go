@@ -0,0 +1,93 @@
+package ldtest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSuppressionsYAML = `
+suppressions:
+  - test: parent/skipped (with parens)
+    reason: not implemented
+    ticket: https://example.com/1
+  - pattern: parent/non-crit.*
+    reason: flaky
+    mode: non-critical
+    expires: 2020-01-31
+  - test: parent/nonexistent
+`
+
+func TestParseSuppressions(t *testing.T) {
+	s, err := ParseSuppressions([]byte(testSuppressionsYAML))
+	require.NoError(t, err)
+
+	assert.Nil(t, s.Match(TestID{"parent"}))
+	assert.Nil(t, s.Match(TestID{"parent", "skipped (with parens) and more"}))
+
+	m := s.Match(TestID{"parent", "skipped (with parens)", "subtest"})
+	require.NotNil(t, m)
+	assert.Equal(t, SuppressionModeSkip, m.Mode)
+	assert.Equal(t, "suppressed: not implemented (https://example.com/1)", m.Explanation())
+
+	m = s.Match(TestID{"parent", "non-critical test"})
+	require.NotNil(t, m)
+	assert.Equal(t, SuppressionModeNonCritical, m.Mode)
+
+	assert.False(t, m.IsExpired(time.Date(2020, 1, 31, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, m.IsExpired(time.Date(2020, 2, 1, 1, 0, 0, 0, time.UTC)))
+
+	unmatched := s.Unmatched()
+	require.Len(t, unmatched, 1)
+	assert.Equal(t, "parent/nonexistent", unmatched[0].String())
+}
+
+func TestParseSuppressionsJSON(t *testing.T) {
+	s, err := ParseSuppressions([]byte(`{"suppressions": [{"test": "a/b", "mode": "non-critical"}]}`))
+	require.NoError(t, err)
+	m := s.Match(TestID{"a", "b"})
+	require.NotNil(t, m)
+	assert.Equal(t, SuppressionModeNonCritical, m.Mode)
+}
+
+func TestParseSuppressionsErrors(t *testing.T) {
+	for _, data := range []string{
+		`suppressions: [{reason: "no test or pattern"}]`,
+		`suppressions: [{test: a, pattern: b}]`,
+		`suppressions: [{test: a, mode: bad}]`,
+		`suppressions: [{test: a, expires: tomorrow}]`,
+		`suppressions: [{pattern: "a["}]`,
+	} {
+		_, err := ParseSuppressions([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestSuppressionsAppliedByRegexFilters(t *testing.T) {
+	s, err := ParseSuppressions([]byte(testSuppressionsYAML))
+	require.NoError(t, err)
+	logger := &recordingTestLogger{}
+	results := Run(TestConfiguration{Filter: RegexFilters{Suppressions: s}, TestLogger: logger}, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Run("skipped (with parens)", func(ldt1 *T) {
+				assert.Fail(t, "should not have run")
+			})
+			ldt0.Run("non-critical test", func(ldt1 *T) {
+				ldt1.Errorf("failed")
+			})
+		})
+	})
+
+	assert.Contains(t, logger.events, "skipped parent/skipped (with parens)")
+	assert.True(t, results.OK())
+	require.Len(t, results.NonCriticalFailures, 1)
+	assert.Equal(t, "suppressed: flaky", results.NonCriticalFailures[0].Explanation)
+
+	var buf bytes.Buffer
+	s.WriteExpiryWarnings(&buf, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, `WARNING: suppression for "parent/non-crit.*" expired on 2020-01-31; suppressed: flaky`+"\n",
+		buf.String())
+}
@@ -18,6 +18,7 @@ var consoleTestFailedNonCriticalColor = color.New(color.FgYellow)       //nolint
 var consoleTestSkippedColor = color.New(color.Faint, color.FgBlue)      //nolint:gochecknoglobals
 var consoleFailedDebugOutputColor = color.New(color.Faint, color.FgRed) //nolint:gochecknoglobals
 var consolePassedDebugOutputColor = color.New(color.Faint)              //nolint:gochecknoglobals
+var consoleTestFlakyColor = color.New(color.FgYellow)                   //nolint:gochecknoglobals
 var allTestsPassedColor = color.New(color.FgGreen)                      //nolint:gochecknoglobals
 
 type TestLogger interface {
@@ -108,6 +109,9 @@ func (c ConsoleTestLogger) TestError(id TestID, err error) {
 
 func (c ConsoleTestLogger) TestFinished(id TestID, result TestResult, debugOutput framework.CapturedOutput) {
 	debugOutputColor := consolePassedDebugOutputColor
+	if result.Flaky {
+		_, _ = consoleTestFlakyColor.Printf("  FLAKY: %s (passed on attempt %d)\n", id, result.Attempts)
+	}
 	if result.Failed() {
 		debugOutputColor = consoleFailedDebugOutputColor
 		if result.NonCritical {
@@ -133,7 +137,9 @@ func (c ConsoleTestLogger) TestSkipped(id TestID, reason string) {
 
 func (c ConsoleTestLogger) EndLog(results Results) error {
 	if results.OK() {
-		if len(results.NonCriticalFailures) == 0 {
+		if len(results.Flaky) != 0 {
+			_, _ = allTestsPassedColor.Println("All tests passed, but some only after being retried")
+		} else if len(results.NonCriticalFailures) == 0 {
 			_, _ = allTestsPassedColor.Println("All tests passed")
 		} else {
 			_, _ = allTestsPassedColor.Println("All critical tests passed")
@@ -152,7 +158,15 @@ func (c ConsoleTestLogger) EndLog(results Results) error {
 		}
 	}
 
-	if !results.OK() {
+	if len(results.Flaky) != 0 {
+		helpers.MustFprintln(os.Stderr)
+		_, _ = consoleTestFlakyColor.Fprintf(os.Stderr, "FLAKY TESTS (%d):\n", len(results.Flaky))
+		for _, f := range results.Flaky {
+			_, _ = consoleTestFlakyColor.Fprintf(os.Stderr, "  * %s (passed on attempt %d)\n", f.TestID, f.Attempts)
+		}
+	}
+
+	if len(results.Failures) != 0 {
 		helpers.MustFprintln(os.Stderr)
 		_, _ = consoleTestFailedColor.Fprintf(os.Stderr, "FAILED TESTS (%d):\n", len(results.Failures))
 		for _, f := range results.Failures {
@@ -160,6 +174,15 @@ func (c ConsoleTestLogger) EndLog(results Results) error {
 		}
 	}
 
+	if results.ServiceLost != nil {
+		helpers.MustFprintln(os.Stderr)
+		_, _ = consoleTestFailedColor.Fprintf(os.Stderr, "TEST SERVICE WAS LOST: %s\n", results.ServiceLost)
+		_, _ = consoleTestFailedColor.Fprintf(os.Stderr, "NOT RUN (%d):\n", len(results.NotRun))
+		for _, id := range results.NotRun {
+			_, _ = consoleTestFailedColor.Fprintf(os.Stderr, "  * %s\n", id)
+		}
+	}
+
 	return nil
 }
 
@@ -3,21 +3,30 @@ package ldtest
 import (
 	"errors"
 	"fmt"
+	"math"
 	"runtime"
-	"runtime/debug"
+	"sync"
+	"time"
 
 	"github.com/launchdarkly/sdk-test-harness/v2/framework"
 )
 
 type environment struct {
-	config  TestConfiguration
-	results Results
+	config           TestConfiguration
+	results          Results
+	workers          chan struct{} // nil unless parallel execution is enabled
+	capabilityChecks map[string][]TestID
+	serviceLost      error
+	lock             sync.Mutex
 }
 
 // T represents a test scope. It is very similar to Go's testing.T type.
 type T struct {
 	env         *environment
 	id          TestID
+	parent      *T
+	context     interface{}
+	events      *eventQueue
 	debugLogger framework.CapturingLogger
 	nonCritical string
 	failed      bool
@@ -26,6 +35,25 @@ type T struct {
 	cleanups    []func()
 	errors      []error
 	helperFns   []string
+	timeout     time.Duration
+	watchdog    *watchdog
+	abandoned   bool // see Timeout
+	hasSubtests bool
+
+	// These fields are only used for retrying a failed test; see TestConfiguration.RetryFailures.
+	initial       subtestParams
+	attempt       int
+	retrying      bool
+	retriedErrors []error
+
+	// These fields are only used if parallel execution is enabled; see parallel.go.
+	isParallel       bool
+	started          chan struct{} // closed when the parent's call to Run can return
+	startedOnce      sync.Once
+	release          chan struct{} // closed when a parallel test can start running
+	done             chan struct{} // closed when the test has finished
+	parallelSubtests []*T
+	lock             sync.Mutex
 }
 
 // TestConfiguration contains options for the entire test run.
@@ -44,6 +72,39 @@ type TestConfiguration struct {
 
 	// EnableLongRunningTests indicates whether tests marked with LongRunning() should be run.
 	EnableLongRunningTests bool
+
+	// MaxParallel is the maximum number of tests that can run at the same time, if they have called
+	// T.Parallel(). If it is 0 or 1, all tests run sequentially.
+	MaxParallel int
+
+	// CheckService is an optional function that is called before each test starts, to find out
+	// whether the test service is still available. Once it has returned an error, no more tests are
+	// run; each test that would have started is reported as skipped, and is listed in Results.NotRun.
+	CheckService func() error
+
+	// DefaultTimeout is the maximum amount of time that each test can take, unless it calls
+	// T.Timeout. If it is zero, there is no limit.
+	DefaultTimeout time.Duration
+
+	// RetryFailures is the number of times to run a failed test again before reporting it as a
+	// failure. Only tests that have no subtests are retried, and only if the failure was not
+	// non-critical and was not a timeout. If a retry passes, the test is reported as flaky.
+	RetryFailures int
+
+	// DryRun indicates that the tests are only being walked to find out what tests there are, as for
+	// ListTestLogger, and their assertions are meaningless; for instance, there is no real test
+	// service. In a dry run, FailNow does not stop a test, since the rest of the test might start more
+	// subtests. Also, every test runs as if it had called Parallel, with no limit on how many run at
+	// once, so that tests that are waiting for something that will never happen all wait at once.
+	DryRun bool
+}
+
+// subtestParams are the properties of a subtest that it inherits when it is started, and that are
+// restored if it is retried.
+type subtestParams struct {
+	context     interface{}
+	nonCritical string
+	timeout     time.Duration
 }
 
 // Run starts a top-level test scope.
@@ -57,50 +118,88 @@ func Run(
 	env := &environment{
 		config: config,
 	}
-	t := &T{env: env}
+	maxParallel := config.MaxParallel
+	if config.DryRun {
+		maxParallel = math.MaxInt32
+	}
+	if maxParallel > 1 {
+		env.workers = make(chan struct{}, maxParallel)
+		env.acquireWorker() // the top-level test occupies the first slot
+	}
+	t := &T{env: env, context: config.Context, events: newEventQueue(nil, false), timeout: config.DefaultTimeout}
 	t.run(action)
+	env.results.CapabilityChecks = env.capabilityChecks
+	env.results.ServiceLost = env.serviceLost
 	return env.results
 }
 
 func (t *T) run(action func(*T)) (result TestResult) {
 	result.TestID = t.id
-	defer func() {
-		if r := recover(); r != nil {
-			if t.skipped {
-				return
-			}
-			t.failed = true
-			var addError error
-			if _, ok := r.(*T); ok {
-				if len(t.errors) == 0 {
-					addError = errors.New("test failed with no failure message")
-				}
-			} else {
-				addError = fmt.Errorf("unexpected panic in test: %+v\n%s", r, string(debug.Stack()))
-			}
-			if addError != nil {
-				t.errors = append(t.errors, addError)
-				t.env.config.TestLogger.TestError(t.id, addError)
+	startTime := time.Now()
+	t.watchdog = newWatchdog(t.timeout)
+	defer t.watchdog.stop()
+
+	incomplete := false
+	if r, stack := t.runWithTimeout(action); r != nil && !t.skipped {
+		incomplete = true
+		t.failed = true
+		var addError error
+		if _, ok := r.(*T); ok {
+			if len(t.errors) == 0 {
+				addError = errors.New("test failed with no failure message")
 			}
+		} else {
+			addError = fmt.Errorf("unexpected panic in test: %+v\n%s", r, string(stack))
 		}
+		if addError != nil {
+			t.errors = append(t.errors, addError)
+			t.emit(func(l TestLogger) { l.TestError(t.id, addError) })
+		}
+	}
+	t.finishParallelSubtests()
+	t.retrying = !t.skipped && t.shouldRetry()
+	if !t.skipped && !t.retrying {
 		result.Errors = t.errors
-		if t.failed {
-			if t.nonCritical == "" {
-				t.env.results.Failures = append(t.env.results.Failures, result)
+		result.Duration = time.Since(startTime)
+		result.Incomplete = incomplete
+		if t.failed && t.nonCritical != "" {
+			result.Explanation = t.nonCritical
+			result.NonCritical = true
+		}
+		result.Attempts = t.attempt
+		if len(t.retriedErrors) != 0 {
+			result.RetriedErrors = t.retriedErrors
+			result.Flaky = !t.failed
+		}
+		t.recordResult(result, t.failed)
+	}
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+	return result
+}
+
+func (t *T) recordResult(result TestResult, failed bool) {
+	env := t.env
+	t.events.emit(func() {
+		if result.Flaky {
+			env.results.Flaky = append(env.results.Flaky, result)
+		} else if failed {
+			if result.NonCritical {
+				env.results.NonCriticalFailures = append(env.results.NonCriticalFailures, result)
 			} else {
-				result.Explanation = t.nonCritical
-				result.NonCritical = true
-				t.env.results.NonCriticalFailures = append(t.env.results.NonCriticalFailures, result)
+				env.results.Failures = append(env.results.Failures, result)
 			}
 		}
-		t.env.results.Tests = append(t.env.results.Tests, result)
-		for i := len(t.cleanups) - 1; i >= 0; i-- {
-			t.cleanups[i]()
-		}
-	}()
+		env.results.Tests = append(env.results.Tests, result)
+	})
+}
 
-	action(t)
-	return result
+// emit sends a TestLogger call through this test's event queue, so that it is delivered in the
+// right order even if tests are running in parallel.
+func (t *T) emit(fn func(TestLogger)) {
+	logger := t.env.config.TestLogger
+	t.events.emit(func() { fn(logger) })
 }
 
 // ID returns the full name of the current test.
@@ -112,24 +211,102 @@ func (t *T) ID() TestID {
 //
 // This is equivalent to Go's testing.T.Run.
 func (t *T) Run(name string, action func(*T)) {
+	t.lock.Lock()
+	t.checkAbandoned()
+	t.hasSubtests = true
+	t.lock.Unlock()
 	id := t.id.Plus(name)
 
-	t.env.config.TestLogger.TestStarted(id)
-	if t.env.config.Filter != nil && !t.env.config.Filter.Match(id) {
-		t.env.config.TestLogger.TestSkipped(id, "excluded by filter parameters")
+	match, skipReason, nonCritical := true, "", ""
+	if f, ok := t.env.config.Filter.(DetailedFilter); ok {
+		match, skipReason, nonCritical = f.MatchDetails(id)
+	} else if t.env.config.Filter != nil && !t.env.config.Filter.Match(id) {
+		match, skipReason = false, "excluded by filter parameters"
+	}
+	logger := t.env.config.TestLogger
+	if !match {
+		t.emitForSubtest(func() {
+			logger.TestStarted(id)
+			logger.TestSkipped(id, skipReason)
+		})
+		return
+	}
+	if err := t.env.checkService(); err != nil {
+		env := t.env
+		reason := fmt.Sprintf("not run, because the test service was lost: %s", err)
+		t.emitForSubtest(func() {
+			logger.TestStarted(id)
+			logger.TestSkipped(id, reason)
+			env.results.NotRun = append(env.results.NotRun, id)
+		})
 		return
 	}
+	params := subtestParams{context: t.context, nonCritical: nonCritical, timeout: t.timeout}
 	c1 := &T{
-		id:  id,
-		env: t.env,
+		id:          id,
+		env:         t.env,
+		parent:      t,
+		context:     params.context,
+		events:      newEventQueue(t.events, t.env.parallelEnabled()),
+		nonCritical: params.nonCritical,
+		timeout:     params.timeout,
+		initial:     params,
+		attempt:     1,
 	}
+	// The subtest has its own time limit, so time spent waiting for it doesn't count toward ours.
+	t.watchdog.pause()
+	defer t.watchdog.resume()
+	c1.events.hold(func() { logger.TestStarted(id) })
 	t.debugLogger.AddChildLogger(&c1.debugLogger) // see comments on t.DebugLogger()
-	result := c1.run(action)
-	t.debugLogger.RemoveChildLogger(&c1.debugLogger)
-	if c1.skipped {
-		t.env.config.TestLogger.TestSkipped(id, c1.skipReason)
+	if !t.env.parallelEnabled() {
+		c1.runSubtest(action)
+		return
+	}
+	// The subtest runs on its own goroutine so that, if it calls Parallel(), we can return from
+	// Run while it waits.
+	c1.started = make(chan struct{})
+	c1.done = make(chan struct{})
+	go c1.runSubtest(action)
+	<-c1.started
+}
+
+// checkService returns the error from TestConfiguration.CheckService, if any. After the first error,
+// it does not call CheckService again, since there is no way to recover from losing the test service.
+func (env *environment) checkService() error {
+	if env.config.CheckService == nil {
+		return nil
+	}
+	env.lock.Lock()
+	defer env.lock.Unlock()
+	if env.serviceLost == nil {
+		env.serviceLost = env.config.CheckService()
+	}
+	return env.serviceLost
+}
+
+func (t *T) runSubtest(action func(*T)) {
+	if t.env.config.DryRun {
+		testAction := action
+		action = func(t *T) {
+			t.Parallel()
+			testAction(t)
+		}
+	}
+	result := t.run(action)
+	for t.retrying {
+		t.prepareRetry()
+		result = t.run(action)
+	}
+	t.parent.debugLogger.RemoveChildLogger(&t.debugLogger)
+	if t.skipped {
+		t.emit(func(l TestLogger) { l.TestSkipped(t.id, t.skipReason) })
 	} else {
-		t.env.config.TestLogger.TestFinished(id, result, c1.debugLogger.Output())
+		output := t.debugLogger.Output()
+		t.emit(func(l TestLogger) { l.TestFinished(t.id, result, output) })
+	}
+	if t.done != nil {
+		close(t.done)
+		t.signalStarted()
 	}
 }
 
@@ -156,27 +333,42 @@ func (t *T) LongRunning() {
 // You will rarely use this method directly; it is part of this type's implementation of the base
 // interfaces testing.T and assert.TestingT, allowing it to be called from assertion helpers.
 func (t *T) Errorf(format string, args ...interface{}) {
-	t.failed = true
 	err := fmt.Errorf(format, args...)
 
 	stacktrace := getStacktrace(false, t.helperFns)
 	err = transformError(err, stacktrace)
 
+	t.lock.Lock()
+	t.checkAbandoned()
+	t.failed = true
 	t.errors = append(t.errors, err)
-	t.env.config.TestLogger.TestError(t.id, err)
+	t.lock.Unlock()
+	t.emit(func(l TestLogger) { l.TestError(t.id, err) })
 }
 
 // FailNow causes the test to immediately terminate and be marked as failed.
 //
 // You will rarely use this method directly; it is part of this type's implementation of the base
 // interfaces testing.T and assert.TestingT, allowing it to be called from assertion helpers.
+//
+// In a dry run (see TestConfiguration.DryRun), the test is marked as failed but keeps running.
 func (t *T) FailNow() {
+	if t.env.config.DryRun {
+		t.lock.Lock()
+		t.checkAbandoned()
+		t.failed = true
+		t.lock.Unlock()
+		return
+	}
 	panic(t)
 }
 
 // Skip causes the test to immediately terminate and be marked as skipped.
 func (t *T) Skip() {
+	t.lock.Lock()
+	t.checkAbandoned()
 	t.skipped = true
+	t.lock.Unlock()
 	panic(t)
 }
 
@@ -205,17 +397,35 @@ func (t *T) DebugLogger() framework.Logger {
 	return &t.debugLogger
 }
 
+// SubtestLogger returns a Logger that writes to the output of whichever subtests of this scope are
+// running at the time. Unlike DebugLogger, output that is written while no subtest is running is
+// discarded. This is meant for output from outside of the test logic, such as the test service's own
+// log output, so that each test's output includes only what happened while that test was running.
+func (t *T) SubtestLogger() framework.Logger {
+	return t.debugLogger.ChildrenOnlyLogger()
+}
+
 // Defer schedules a cleanup function which is guaranteed to be called when this test scope
 // exits for any reason. Unlike a Go defer statement, Defer can be used from within helper
 // functions.
 func (t *T) Defer(cleanupFn func()) {
+	t.lock.Lock()
+	t.checkAbandoned()
 	t.cleanups = append(t.cleanups, cleanupFn)
+	t.lock.Unlock()
 }
 
 // Context returns the application-defined context value, if any, that was specified in the
-// TestConfiguration.
+// TestConfiguration or in SetContext.
 func (t *T) Context() interface{} {
-	return t.env.config.Context
+	return t.context
+}
+
+// SetContext replaces the application-defined context value for this test scope and any subtests
+// that it starts afterward. This allows a test to change some state that helper code depends on,
+// without affecting other tests that might be running in parallel.
+func (t *T) SetContext(context interface{}) {
+	t.context = context
 }
 
 // Capabilities returns the capabilities reported by the test service.
@@ -225,6 +435,7 @@ func (t *T) Capabilities() framework.Capabilities {
 
 // RequireCapability causes the test to be skipped if Capabilities().Has(name) returns false.
 func (t *T) RequireCapability(name string) {
+	t.recordCapabilityChecks(name)
 	if !t.Capabilities().Has(name) {
 		t.SkipWithReason(fmt.Sprintf("test service does not have capability %q", name))
 	}
@@ -232,11 +443,26 @@ func (t *T) RequireCapability(name string) {
 
 // RequireCapabilities causes the test to be skipped if Capabilities().HasAll(names) returns false.
 func (t *T) RequireCapabilities(names ...string) {
+	t.recordCapabilityChecks(names...)
 	if !t.Capabilities().HasAll(names...) {
 		t.SkipWithReason(fmt.Sprintf("test service does not have all of the required capabilities: %v", names))
 	}
 }
 
+func (t *T) recordCapabilityChecks(names ...string) {
+	t.env.lock.Lock()
+	defer t.env.lock.Unlock()
+	if t.env.capabilityChecks == nil {
+		t.env.capabilityChecks = make(map[string][]TestID)
+	}
+	for _, name := range names {
+		ids := t.env.capabilityChecks[name]
+		if len(ids) == 0 || ids[len(ids)-1].String() != t.id.String() {
+			t.env.capabilityChecks[name] = append(ids, t.id)
+		}
+	}
+}
+
 // Helper marks the function that calls it as a test helper that shouldn't appear in stacktraces.
 // Equivalent to Go's testing.T.Helper().
 func (t *T) Helper() {
@@ -250,3 +476,22 @@ func (t *T) Helper() {
 	}
 	t.helperFns = append(t.helperFns, f.Name())
 }
+
+func (t *T) shouldRetry() bool {
+	return t.failed && t.nonCritical == "" && !t.hasSubtests && !t.abandoned && t.parent != nil &&
+		t.attempt <= t.env.config.RetryFailures
+}
+
+// prepareRetry resets the state of a failed test so that it can run again. The output of the earlier
+// attempts is kept, so the debug output for the test will show every attempt.
+func (t *T) prepareRetry() {
+	t.retriedErrors = append(t.retriedErrors, t.errors...)
+	t.attempt++
+	t.failed, t.skipped, t.skipReason = false, false, ""
+	t.errors, t.cleanups, t.helperFns = nil, nil, nil
+	t.context, t.nonCritical, t.timeout = t.initial.context, t.initial.nonCritical, t.initial.timeout
+	t.Debug("Test failed; retrying (attempt %d of %d)", t.attempt, t.env.config.RetryFailures+1)
+	if t.isParallel {
+		t.env.acquireWorker() // the worker slot was released when the previous attempt finished
+	}
+}
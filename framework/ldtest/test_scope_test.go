@@ -1,6 +1,7 @@
 package ldtest
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/launchdarkly/sdk-test-harness/v2/framework"
@@ -45,6 +46,36 @@ func TestTestScopeExitsImmediatelyOnFailNow(t *testing.T) {
 	assert.True(t, executed3)
 }
 
+func TestTestScopeIsMarkedIncompleteIfItDoesNotRunToTheEnd(t *testing.T) {
+	result := Run(TestConfiguration{}, func(ldt *T) {
+		ldt.Run("fails", func(ldt1 *T) { ldt1.Errorf("failed") })
+		ldt.Run("fails now", func(ldt1 *T) { ldt1.FailNow() })
+		ldt.Run("panics", func(ldt1 *T) { panic("oops") })
+	})
+	require.Len(t, result.Tests, 4)
+	assert.False(t, result.Tests[0].Incomplete)
+	assert.True(t, result.Tests[1].Incomplete)
+	assert.True(t, result.Tests[2].Incomplete)
+}
+
+func TestTestScopeDryRun(t *testing.T) {
+	executed := false
+	otherStarted := make(chan struct{})
+	result := Run(TestConfiguration{DryRun: true}, func(ldt *T) {
+		ldt.Run("fails", func(ldt1 *T) {
+			ldt1.FailNow()
+			ldt1.Run("subtest", func(ldt2 *T) { executed = true })
+		})
+		// Every test runs in parallel, so this one would block forever if the next one couldn't start.
+		ldt.Run("waits", func(ldt1 *T) { <-otherStarted })
+		ldt.Run("other", func(ldt1 *T) { close(otherStarted) })
+	})
+	assert.True(t, executed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, TestID{"fails"}, result.Failures[0].TestID)
+	assert.False(t, result.Failures[0].Incomplete)
+}
+
 func TestTestScopeExitsImmediatelyOnSkip(t *testing.T) {
 	executed1 := false
 	executed2 := false
@@ -288,3 +319,69 @@ func TestParentTestLoggerIsCopiedToChildTestLogger(t *testing.T) {
 		}, outputLines(ldt))
 	})
 }
+
+func TestSubtestLoggerOnlyWritesToRunningSubtests(t *testing.T) {
+	outputLines := func(ldt *T) []string {
+		var ret []string
+		for _, m := range ldt.debugLogger.Output() {
+			ret = append(ret, m.Message)
+		}
+		return ret
+	}
+
+	Run(TestConfiguration{}, func(ldt *T) {
+		ldt.SubtestLogger().Println("external log 1")
+
+		ldt.Run("child1", func(ldt1 *T) {
+			ldt1.DebugLogger().Println("child1 log 1")
+			ldt.SubtestLogger().Println("external log 2")
+
+			assert.Equal(t, []string{"child1 log 1", "external log 2"}, outputLines(ldt1))
+		})
+
+		ldt.SubtestLogger().Println("external log 3")
+
+		ldt.Run("child2", func(ldt2 *T) {
+			ldt.SubtestLogger().Printf("external log %d", 4)
+
+			assert.Equal(t, []string{"external log 4"}, outputLines(ldt2))
+		})
+
+		assert.Len(t, outputLines(ldt), 0)
+	})
+}
+
+func TestTestScopeStopsRunningTestsWhenServiceIsLost(t *testing.T) {
+	serviceLost := false
+	var executed []string
+	config := TestConfiguration{
+		CheckService: func() error {
+			if serviceLost {
+				return errors.New("service is gone")
+			}
+			return nil
+		},
+	}
+	result := Run(config, func(ldt *T) {
+		ldt.Run("parent", func(ldt0 *T) {
+			ldt0.Run("subtest1", func(ldt1 *T) {
+				executed = append(executed, "subtest1")
+				serviceLost = true
+				ldt1.Errorf("lost connection")
+			})
+			ldt0.Run("subtest2", func(ldt2 *T) {
+				executed = append(executed, "subtest2")
+			})
+		})
+		ldt.Run("other", func(ldt0 *T) {
+			executed = append(executed, "other")
+		})
+	})
+
+	assert.Equal(t, []string{"subtest1"}, executed)
+	assert.False(t, result.OK())
+	assert.EqualError(t, result.ServiceLost, "service is gone")
+	assert.Equal(t, []TestID{{"parent", "subtest2"}, {"other"}}, result.NotRun)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, TestID{"parent", "subtest1"}, result.Failures[0].TestID)
+}
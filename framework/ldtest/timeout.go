@@ -0,0 +1,171 @@
+package ldtest
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout sets the maximum amount of time that this test can take, replacing the default from
+// TestConfiguration.DefaultTimeout. It also becomes the default for any subtests that are started
+// after this call. Zero means there is no limit.
+//
+// Time that the test spends waiting for its subtests, or waiting to start after calling Parallel,
+// does not count toward the limit; a subtest that takes too long will fail on its own account.
+//
+// If the test is still running when the time is up, it fails with an error that includes a dump of
+// all goroutines in the test harness and the test's debug output so far. The test function cannot
+// be stopped from outside, so it is abandoned: the test run moves on, and if the test function ever
+// tries to interact with the test scope again, it is terminated as if it had called FailNow.
+func (t *T) Timeout(timeout time.Duration) {
+	t.timeout = timeout
+	t.watchdog.setTimeout(timeout)
+}
+
+// testGoroutineFunctionName is the name of the function at the root of the goroutine that runs each
+// test function, as it appears in stacktraces.
+const testGoroutineFunctionName = "(*T).runWithTimeout.func1"
+
+type panicInfo struct {
+	value interface{}
+	stack []byte
+}
+
+// runWithTimeout calls the test function on a separate goroutine, so that we can stop waiting for
+// it if the watchdog expires. It returns the value that the function panicked with, if any.
+func (t *T) runWithTimeout(action func(*T)) (recovered interface{}, stack []byte) {
+	result := make(chan panicInfo, 1)
+	go func() {
+		var p panicInfo
+		defer func() {
+			if r := recover(); r != nil {
+				p = panicInfo{r, debug.Stack()}
+			}
+			result <- p
+		}()
+		action(t)
+	}()
+	select {
+	case p := <-result:
+		return p.value, p.stack
+	case <-t.watchdog.expired:
+		t.abandon()
+		return t, nil // same as if the test had called FailNow
+	}
+}
+
+func (t *T) abandon() {
+	err := fmt.Errorf("test timed out after %s\n\nDebug output so far:\n%s\n\nGoroutine dump:\n%s",
+		t.timeout, t.debugLogger.Output().ToString("  "), goroutineDump())
+	t.lock.Lock()
+	t.abandoned = true
+	t.failed = true
+	t.errors = append(t.errors, err)
+	t.lock.Unlock()
+	t.emit(func(l TestLogger) { l.TestError(t.id, err) })
+}
+
+// checkAbandoned terminates the calling goroutine if the test has timed out. It must be called while
+// holding t.lock.
+func (t *T) checkAbandoned() {
+	if t.abandoned {
+		t.lock.Unlock()
+		panic(t)
+	}
+}
+
+func goroutineDump() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// watchdog measures the time that a test has been running, excluding any time when it is paused.
+// The expired channel is closed when the total reaches the timeout.
+type watchdog struct {
+	timeout    time.Duration
+	used       time.Duration
+	resumedAt  time.Time
+	pauses     int
+	timer      *time.Timer
+	expired    chan struct{}
+	expireOnce sync.Once
+	lock       sync.Mutex
+}
+
+func newWatchdog(timeout time.Duration) *watchdog {
+	w := &watchdog{timeout: timeout, resumedAt: time.Now(), expired: make(chan struct{})}
+	w.lock.Lock()
+	w.schedule()
+	w.lock.Unlock()
+	return w
+}
+
+func (w *watchdog) setTimeout(timeout time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.timeout = timeout
+	if w.pauses == 0 {
+		w.stopTimer()
+		w.schedule()
+	}
+}
+
+// pause and resume can be nested, since a test can be waiting for more than one thing at once.
+func (w *watchdog) pause() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pauses++
+	if w.pauses == 1 {
+		w.stopTimer()
+	}
+}
+
+func (w *watchdog) resume() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pauses--
+	if w.pauses == 0 {
+		w.schedule()
+	}
+}
+
+func (w *watchdog) stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pauses++
+	w.stopTimer()
+}
+
+// schedule and stopTimer must be called while holding the lock.
+func (w *watchdog) schedule() {
+	w.resumedAt = time.Now()
+	if w.timeout <= 0 {
+		return
+	}
+	remaining := w.timeout - w.used
+	if remaining <= 0 {
+		w.expire()
+		return
+	}
+	w.timer = time.AfterFunc(remaining, w.expire)
+}
+
+func (w *watchdog) stopTimer() {
+	w.used += time.Since(w.resumedAt)
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+func (w *watchdog) expire() {
+	w.expireOnce.Do(func() { close(w.expired) })
+}
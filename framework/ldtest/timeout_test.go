@@ -0,0 +1,73 @@
+package ldtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestTimesOut(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	abandonedTestEnded := make(chan struct{})
+	nextTestRan := false
+
+	result := Run(TestConfiguration{DefaultTimeout: time.Millisecond * 50}, func(ldt *T) {
+		ldt.Run("stuck", func(ldt1 *T) {
+			defer close(abandonedTestEnded)
+			ldt1.Debug("about to get stuck")
+			<-block
+			ldt1.Errorf("this error should not be recorded")
+		})
+		ldt.Run("next", func(ldt1 *T) {
+			nextTestRan = true
+		})
+	})
+
+	assert.True(t, nextTestRan)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, TestID{"stuck"}, result.Failures[0].TestID)
+	require.Len(t, result.Failures[0].Errors, 1)
+	message := result.Failures[0].Errors[0].Error()
+	assert.Contains(t, message, "test timed out after 50ms")
+	assert.Contains(t, message, "about to get stuck")
+	assert.Contains(t, message, "Goroutine dump:")
+	assert.Contains(t, message, "TestTestTimesOut")
+
+	block <- struct{}{}
+	<-abandonedTestEnded
+	assert.Len(t, result.Failures[0].Errors, 1)
+}
+
+func TestTestTimeoutCanBeOverridden(t *testing.T) {
+	result := Run(TestConfiguration{DefaultTimeout: time.Millisecond * 20}, func(ldt *T) {
+		ldt.Run("parent", func(ldt1 *T) {
+			ldt1.Timeout(time.Second)
+			ldt1.Run("inherits timeout", func(ldt2 *T) {
+				time.Sleep(time.Millisecond * 50)
+			})
+		})
+		ldt.Run("no timeout", func(ldt1 *T) {
+			ldt1.Timeout(0)
+			time.Sleep(time.Millisecond * 50)
+		})
+	})
+
+	assert.True(t, result.OK(), "failures: %+v", result.Failures)
+}
+
+func TestTimeSpentInSubtestsDoesNotCountTowardTimeout(t *testing.T) {
+	result := Run(TestConfiguration{DefaultTimeout: time.Millisecond * 100}, func(ldt *T) {
+		ldt.Run("parent", func(ldt1 *T) {
+			for _, name := range []string{"a", "b", "c"} {
+				ldt1.Run(name, func(ldt2 *T) {
+					time.Sleep(time.Millisecond * 60)
+				})
+			}
+		})
+	})
+
+	assert.True(t, result.OK(), "failures: %+v", result.Failures)
+}
@@ -59,6 +59,36 @@ func (l *CapturingLogger) append(m CapturedMessage) {
 	}
 }
 
+// ChildrenOnlyLogger returns a Logger that sends output to whichever child loggers are attached to
+// this one at the time. If there are none, the output is discarded, rather than being kept and copied
+// into children that are added later. This is for output that comes from outside of the test logic,
+// such as the test service's own log output, which is only relevant to the tests that are running.
+func (l *CapturingLogger) ChildrenOnlyLogger() Logger {
+	return childrenOnlyLogger{l}
+}
+
+type childrenOnlyLogger struct {
+	owner *CapturingLogger
+}
+
+func (c childrenOnlyLogger) Println(args ...interface{}) {
+	m := strings.TrimRight(fmt.Sprintln(args...), "\r\n")
+	c.appendToChildren(CapturedMessage{Time: time.Now(), Message: m})
+}
+
+func (c childrenOnlyLogger) Printf(message string, args ...interface{}) {
+	c.appendToChildren(CapturedMessage{Time: time.Now(), Message: fmt.Sprintf(message, args...)})
+}
+
+func (c childrenOnlyLogger) appendToChildren(m CapturedMessage) {
+	c.owner.lock.Lock()
+	children := append([]*CapturingLogger(nil), c.owner.children...)
+	c.owner.lock.Unlock()
+	for _, child := range children {
+		child.append(m)
+	}
+}
+
 func (l *CapturingLogger) Output() CapturedOutput {
 	l.lock.Lock()
 	ret := append([]CapturedMessage(nil), l.output...)
@@ -76,6 +106,14 @@ func (l *CapturingLogger) AddChildLogger(child *CapturingLogger) {
 	child.lock.Unlock()
 }
 
+// ReattachChildLogger is like AddChildLogger, but does not copy the parent's existing output. It is
+// for a child that was previously added and removed, and so already has that output.
+func (l *CapturingLogger) ReattachChildLogger(child *CapturingLogger) {
+	l.lock.Lock()
+	l.children = append(l.children, child)
+	l.lock.Unlock()
+}
+
 func (l *CapturingLogger) RemoveChildLogger(child *CapturingLogger) {
 	l.lock.Lock()
 	for i, c := range l.children {
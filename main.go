@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -15,6 +16,8 @@ import (
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
 	"github.com/launchdarkly/sdk-test-harness/v2/sdktests"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+	"github.com/launchdarkly/sdk-test-harness/v2/serviceinfo"
 )
 
 const defaultPort = 8111
@@ -23,18 +26,33 @@ const versionString string = "2.41.0" // {{ x-release-please-version }}
 func main() {
 	fmt.Printf("sdk-test-harness v%s\n", strings.TrimSpace(versionString))
 
+	if len(os.Args) > 1 && os.Args[1] == compareCommandName {
+		os.Exit(runCompare(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == mergeCommandName {
+		os.Exit(runMerge(os.Args[2:]))
+	}
+
 	var params commandParams
 	if !params.Read(os.Args) {
 		os.Exit(1)
 	}
 
+	if params.list {
+		if err := runList(params); err != nil {
+			helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	results, err := run(params)
 	if err != nil {
 		helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if !results.OK() {
+	if !results.OK() || (params.failOnFlaky && len(results.Flaky) != 0) {
 		os.Exit(1)
 	}
 }
@@ -51,6 +69,20 @@ func run(params commandParams) (*ldtest.Results, error) {
 		mainDebugLogger = log.New(os.Stdout, "", log.LstdFlags)
 	}
 
+	var serviceProcess *harness.ServiceProcess
+	if params.serviceCommand != "" {
+		p, err := harness.StartServiceProcess(params.serviceCommand, params.serviceURL,
+			time.Duration(params.queryTimeoutSeconds)*time.Second, os.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		serviceProcess = p
+		defer func() {
+			fmt.Println("Stopping test service process")
+			serviceProcess.Stop()
+		}()
+	}
+
 	harness, err := harness.NewTestHarness(
 		params.serviceURL,
 		params.host,
@@ -64,25 +96,44 @@ func run(params commandParams) (*ldtest.Results, error) {
 	if err != nil {
 		return nil, err
 	}
+	if serviceProcess != nil {
+		harness.SetServiceProcess(serviceProcess)
+	}
+	harness.SetCommandTimeout(time.Duration(params.commandTimeoutSeconds) * time.Second)
 
 	var testLogger ldtest.TestLogger
 	consoleLogger := ldtest.ConsoleTestLogger{
 		DebugOutputOnFailure: params.debug || params.debugAll,
 		DebugOutputOnSuccess: params.debugAll,
 	}
-	if params.jUnitFile == "" {
+	loggers := []ldtest.TestLogger{consoleLogger}
+	if params.jUnitFile != "" {
+		jUnitLogger := ldtest.NewJUnitTestLogger(params.jUnitFile, harness.TestServiceInfo(), params.filters)
+		jUnitLogger.SetShard(params.shard)
+		loggers = append(loggers, jUnitLogger)
+	}
+	if params.jsonResultsFile != "" {
+		f, err := os.Create(params.jsonResultsFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create JSON results file: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		loggers = append(loggers, ldtest.NewJSONTestLogger(f))
+	}
+	if len(loggers) == 1 {
 		testLogger = consoleLogger
 	} else {
-		testLogger = &ldtest.MultiTestLogger{Loggers: []ldtest.TestLogger{
-			consoleLogger,
-			ldtest.NewJUnitTestLogger(params.jUnitFile, harness.TestServiceInfo(), params.filters),
-		}}
+		testLogger = &ldtest.MultiTestLogger{Loggers: loggers}
 	}
 
 	countingLogger := ldtest.NewCountingTestLogger(testLogger)
 	testLogger = countingLogger
 
-	results := sdktests.RunSDKTestSuite(harness, params.filters, countingLogger, params.enableLongRunningTests)
+	if params.shard.Count > 1 {
+		fmt.Printf("Running shard %s\n", params.shard)
+	}
+	results := sdktests.RunSDKTestSuite(harness, params.testFilter(), countingLogger, params.enableLongRunningTests,
+		params.parallel, time.Duration(params.testTimeoutSeconds)*time.Second, params.retryFailures)
 
 	fmt.Println()
 	logErr := testLogger.EndLog(results)
@@ -91,6 +142,11 @@ func run(params commandParams) (*ldtest.Results, error) {
 	fmt.Printf("Test Summary: %d total, %d skipped, %d ran\n", total, skipped, total-skipped)
 	fmt.Println()
 
+	// When running one shard, most suppressions are for tests in other shards, which were never considered.
+	if params.filters.Suppressions != nil && params.shard.Count <= 1 {
+		params.filters.Suppressions.WriteUnmatched(os.Stdout)
+	}
+
 	if params.stopServiceAtEnd {
 		fmt.Println("Stopping test service")
 		if err := harness.StopService(); err != nil {
@@ -102,6 +158,16 @@ func run(params commandParams) (*ldtest.Results, error) {
 		return nil, fmt.Errorf("error writing log: %v", logErr)
 	}
 
+	if params.capabilityReportFile != "" {
+		fmt.Printf("Writing capability report to %s\n", params.capabilityReportFile)
+		f, err := os.Create(params.capabilityReportFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create capability report file: %v", err)
+		}
+		ldtest.WriteCapabilityReport(f, results, servicedef.AllCapabilities(), harness.TestServiceInfo().Capabilities)
+		_ = f.Close()
+	}
+
 	if params.recordFailures != "" {
 		f, err := os.Create(params.recordFailures)
 		if err != nil {
@@ -116,7 +182,59 @@ func run(params commandParams) (*ldtest.Results, error) {
 	return &results, nil
 }
 
+// runList does a dry run of the test suite with a simulated test service, and prints the tests that
+// would run.
+func runList(params commandParams) error {
+	if params.skipFile != "" {
+		if err := loadSuppressions(&params); err != nil {
+			return err
+		}
+	}
+
+	var info serviceinfo.TestServiceInfo
+	if params.listStatusFile != "" {
+		data, err := os.ReadFile(params.listStatusFile)
+		if err != nil {
+			return fmt.Errorf("cannot read status file: %v", err)
+		}
+		if info, err = serviceinfo.Parse(data); err != nil {
+			return fmt.Errorf("malformed status file: %v", err)
+		}
+	} else {
+		for _, c := range strings.Split(params.listCapabilities, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				info.Capabilities = append(info.Capabilities, c)
+			}
+		}
+	}
+
+	harness := harness.NewDryRunTestHarness(info, params.host, params.port, params.enablePersistenceTests, nil)
+	listLogger := ldtest.NewListTestLogger(os.Stdout)
+	results := sdktests.RunSDKTestSuite(harness, params.testFilter(), listLogger, params.enableLongRunningTests,
+		params.parallel, time.Duration(params.testTimeoutSeconds)*time.Second, 0)
+	if err := listLogger.EndLog(results); err != nil {
+		return err
+	}
+	if params.filters.Suppressions != nil && params.shard.Count <= 1 {
+		fmt.Println()
+		params.filters.Suppressions.WriteUnmatched(os.Stdout)
+	}
+	return nil
+}
+
 func loadSuppressions(params *commandParams) error {
+	switch strings.ToLower(filepath.Ext(params.skipFile)) {
+	case ".yaml", ".yml", ".json":
+		suppressions, err := ldtest.LoadSuppressionsFile(params.skipFile)
+		if err != nil {
+			return fmt.Errorf("cannot load suppression file: %v", err)
+		}
+		suppressions.WriteExpiryWarnings(os.Stdout, time.Now())
+		params.filters.Suppressions = suppressions
+		return nil
+	}
+
+	// Any other file is in the simple format written by -record-failures, with one test ID per line.
 	file, err := os.Open(params.skipFile)
 	if err != nil {
 		return fmt.Errorf("cannot open provided suppression file: %v", err)
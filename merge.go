@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+)
+
+const mergeCommandName = "merge"
+
+type mergeParams struct {
+	outputFile string
+	inputFiles []string
+}
+
+func (m *mergeParams) Read(args []string) bool {
+	fs := flag.NewFlagSet(mergeCommandName, flag.ContinueOnError)
+	fs.StringVar(&m.outputFile, "junit", "", "path of the merged JUnit XML file to write")
+	fs.Usage = func() {
+		helpers.MustFprintln(fs.Output(), "usage: sdk-test-harness merge -junit <output file> <shard results files...>")
+		helpers.MustFprintln(fs.Output(), "the shard results files are the -junit output of test runs that used -shard")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if m.outputFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return false
+	}
+	m.inputFiles = fs.Args()
+	return true
+}
+
+// runMerge implements the merge command, which combines the JUnit output of several shards into one
+// file. It returns the process exit code.
+func runMerge(args []string) int {
+	var params mergeParams
+	if !params.Read(args) {
+		return 1
+	}
+	if err := ldtest.MergeJUnitFiles(params.outputFile, params.inputFiles...); err != nil {
+		helpers.MustFprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Merged %d files into %s\n", len(params.inputFiles), params.outputFile)
+	return 0
+}
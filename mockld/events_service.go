@@ -12,6 +12,8 @@ import (
 	"github.com/launchdarkly/sdk-test-harness/v2/framework"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
 
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+
 	"github.com/gorilla/mux"
 )
 
@@ -25,10 +27,13 @@ const eventsChannelBufferSize = 100
 // to interact with directly; most tests use the sdktests.SDKEventSink facade.
 type EventsService struct {
 	AnalyticsEventPayloads chan Events
+	DiagnosticEvents       chan Event
 	sdkKind                SDKKind
 	ignoreDuplicatePayload bool
 	hostTimeOverride       time.Time
 	payloadIDsSeen         map[string]bool
+	postStatuses           []int
+	postCount              int
 	handler                http.Handler
 	logger                 framework.Logger
 	lock                   sync.Mutex
@@ -37,6 +42,7 @@ type EventsService struct {
 func NewEventsService(sdkKind SDKKind, logger framework.Logger, requireGzip bool) *EventsService {
 	s := &EventsService{
 		AnalyticsEventPayloads: make(chan Events, eventsChannelBufferSize),
+		DiagnosticEvents:       make(chan Event, eventsChannelBufferSize),
 		sdkKind:                sdkKind,
 		ignoreDuplicatePayload: true,
 		payloadIDsSeen:         make(map[string]bool),
@@ -94,6 +100,13 @@ func (s *EventsService) AwaitAnalyticsEventPayload(timeout time.Duration) (Event
 	return ep.Value(), ep.IsDefined()
 }
 
+// AwaitDiagnosticEvent waits for a diagnostic event ("diagnostic-init" or "diagnostic") to be posted.
+// Diagnostic events are delivered separately from analytics events, one per request.
+func (s *EventsService) AwaitDiagnosticEvent(timeout time.Duration) (Event, bool) {
+	e := helpers.TryReceive(s.DiagnosticEvents, timeout)
+	return e.Value(), e.IsDefined()
+}
+
 func (s *EventsService) SetHostTimeOverride(t time.Time) {
 	s.lock.Lock()
 	s.hostTimeOverride = t
@@ -115,6 +128,30 @@ func (s *EventsService) SetIgnoreDuplicatePayload(ignore bool) {
 	s.lock.Unlock()
 }
 
+// SetAnalyticsPostStatuses sets the HTTP status codes that the service will return for analytics event
+// posts, starting with the next one: the first post gets the first status, and so on, and the last status
+// is repeated for all posts after that. For instance, (503, 202) means that the next post fails and all
+// later ones succeed, and (401) means that all posts fail. The default is 202 for every post.
+//
+// A post that receives an error status is not delivered to AnalyticsEventPayloads, and its payload ID
+// does not count as having been seen for the purposes of SetIgnoreDuplicatePayload, so a retry of the
+// same payload can succeed.
+func (s *EventsService) SetAnalyticsPostStatuses(statuses ...int) {
+	s.lock.Lock()
+	s.postStatuses = statuses
+	s.postCount = 0
+	s.lock.Unlock()
+}
+
+func (s *EventsService) nextPostStatus() int {
+	if len(s.postStatuses) == 0 {
+		return http.StatusAccepted
+	}
+	status := s.postStatuses[min(s.postCount, len(s.postStatuses)-1)]
+	s.postCount++
+	return status
+}
+
 func (s *EventsService) postEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -133,7 +170,8 @@ func (s *EventsService) postEvents(w http.ResponseWriter, r *http.Request) {
 	hostTime := s.hostTimeOverride
 	ignoreDuplicatePayload := s.ignoreDuplicatePayload
 	seenPayloadID := s.payloadIDsSeen[payloadID]
-	if payloadID != "" {
+	status := s.nextPostStatus()
+	if payloadID != "" && status < 300 {
 		s.payloadIDsSeen[payloadID] = true
 	}
 	s.lock.Unlock()
@@ -142,6 +180,12 @@ func (s *EventsService) postEvents(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Date", hostTime.UTC().Format(http.TimeFormat))
 	}
 
+	if status >= 300 {
+		w.WriteHeader(status)
+		s.logger.Printf("Rejected event payload ID %q with status %d: %s", payloadID, status, string(data))
+		return
+	}
+
 	if ignoreDuplicatePayload && payloadID != "" && seenPayloadID {
 		w.WriteHeader(http.StatusAccepted)
 		s.logger.Printf("Received & discarded duplicate payload ID %q: %s", payloadID, string(data))
@@ -154,7 +198,7 @@ func (s *EventsService) postEvents(w http.ResponseWriter, r *http.Request) {
 		s.logger.Printf("Received bad event data (%s): %s", err, string(data))
 		return
 	}
-	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(status)
 	s.logger.Printf("Received %d events", len(events))
 	for _, e := range events {
 		s.logger.Printf("    %s", e.JSONString())
@@ -164,5 +208,20 @@ func (s *EventsService) postEvents(w http.ResponseWriter, r *http.Request) {
 
 func (s *EventsService) postDiagnosticEvent(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.logger.Printf("Unable to read request body")
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil || event.AsValue().Type() != ldvalue.ObjectType {
+		w.WriteHeader(http.StatusBadRequest)
+		s.logger.Printf("Received bad diagnostic event data (%s): %s", err, string(data))
+		return
+	}
 	w.WriteHeader(http.StatusAccepted)
+	s.logger.Printf("Received %q diagnostic event: %s", event.Kind(), event.JSONString())
+	s.DiagnosticEvents <- event
 }
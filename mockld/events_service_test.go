@@ -0,0 +1,97 @@
+package mockld
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsServiceQueuesDiagnosticEventsSeparately(t *testing.T) {
+	service := NewEventsService(ServerSideSDK, framework.NullLogger(), false)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		resp := postToEventsService(t, server.URL+"/diagnostic", `{"kind": "diagnostic-init", "id": {}}`)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		event, ok := service.AwaitDiagnosticEvent(time.Second)
+		require.True(t, ok)
+		assert.Equal(t, "diagnostic-init", event.Kind())
+
+		_, ok = service.AwaitAnalyticsEventPayload(time.Millisecond * 50)
+		assert.False(t, ok, "diagnostic event should not be delivered as an analytics payload")
+	})
+}
+
+func TestEventsServiceRejectsMalformedDiagnosticEvent(t *testing.T) {
+	service := NewEventsService(ServerSideSDK, framework.NullLogger(), false)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		for _, body := range []string{`{no`, `[{"kind": "diagnostic"}]`} {
+			resp := postToEventsService(t, server.URL+"/diagnostic", body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "body: %s", body)
+		}
+
+		_, ok := service.AwaitDiagnosticEvent(time.Millisecond * 50)
+		assert.False(t, ok)
+	})
+}
+
+func TestEventsServiceAnalyticsPostStatuses(t *testing.T) {
+	service := NewEventsService(ServerSideSDK, framework.NullLogger(), false)
+	service.SetAnalyticsPostStatuses(503, 202)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		resp := postToEventsServiceWithPayloadID(t, server.URL+"/bulk", `[{"kind": "custom"}]`, "payload1")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		_, ok := service.AwaitAnalyticsEventPayload(time.Millisecond * 50)
+		assert.False(t, ok, "rejected payload should not be delivered")
+
+		// the retry is not treated as a duplicate, since the first post failed
+		resp = postToEventsServiceWithPayloadID(t, server.URL+"/bulk", `[{"kind": "custom"}]`, "payload1")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		_, ok = service.AwaitAnalyticsEventPayload(time.Second)
+		assert.True(t, ok)
+
+		// the last status is repeated
+		resp = postToEventsServiceWithPayloadID(t, server.URL+"/bulk", `[{"kind": "custom"}]`, "payload2")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	})
+}
+
+func TestEventsServiceAnalyticsPostStatusRepeatsForever(t *testing.T) {
+	service := NewEventsService(ServerSideSDK, framework.NullLogger(), false)
+	service.SetAnalyticsPostStatuses(401)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		for _, payloadID := range []string{"payload1", "payload1", "payload2"} {
+			resp := postToEventsServiceWithPayloadID(t, server.URL+"/bulk", `[{"kind": "custom"}]`, payloadID)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+		_, ok := service.AwaitAnalyticsEventPayload(time.Millisecond * 50)
+		assert.False(t, ok)
+	})
+}
+
+func postToEventsService(t *testing.T, url, body string) *http.Response {
+	return postToEventsServiceWithPayloadID(t, url, body, "")
+}
+
+func postToEventsServiceWithPayloadID(t *testing.T, url, body, payloadID string) *http.Response {
+	req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+	if payloadID != "" {
+		req.Header.Set("X-LaunchDarkly-Payload-ID", payloadID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	return resp
+}
@@ -0,0 +1,299 @@
+package mockld
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+)
+
+// Event names used by the FDv2 streaming and polling protocols.
+const (
+	FDv2EventServerIntent       = "server-intent"
+	FDv2EventPutObject          = "put-object"
+	FDv2EventDeleteObject       = "delete-object"
+	FDv2EventPayloadTransferred = "payload-transferred"
+	FDv2EventGoodbye            = "goodbye"
+)
+
+// FDv2BasisQueryParam is the query parameter that an FDv2 SDK uses to report the selector state of
+// the data it already has, so that the service can send only the changes since then.
+const FDv2BasisQueryParam = "basis"
+
+// FDv2IntentCode describes what kind of data transfer the service is about to perform.
+type FDv2IntentCode string
+
+const (
+	// FDv2IntentTransferFull means the service will send a complete data set.
+	FDv2IntentTransferFull FDv2IntentCode = "xfer-full"
+	// FDv2IntentTransferChanges means the service will send only the changes since the SDK's basis.
+	FDv2IntentTransferChanges FDv2IntentCode = "xfer-changes"
+	// FDv2IntentNone means the SDK is already up to date.
+	FDv2IntentNone FDv2IntentCode = "none"
+)
+
+// FDv2Event is a single event in the FDv2 protocol. In a stream it is sent as an SSE event; in a
+// polling response it is an element of the "events" array.
+type FDv2Event struct {
+	Name string          `json:"event"`
+	Data json.RawMessage `json:"data"`
+}
+
+// FDv2ServerIntent is the data of a "server-intent" event.
+type FDv2ServerIntent struct {
+	Payloads []FDv2ServerIntentPayload `json:"payloads"`
+}
+
+// FDv2ServerIntentPayload describes one payload within a "server-intent" event.
+type FDv2ServerIntentPayload struct {
+	ID         string         `json:"id"`
+	Target     int            `json:"target"`
+	IntentCode FDv2IntentCode `json:"intentCode"`
+	Reason     string         `json:"reason"`
+}
+
+// FDv2PutObject is the data of a "put-object" event.
+type FDv2PutObject struct {
+	Version int             `json:"version"`
+	Kind    string          `json:"kind"`
+	Key     string          `json:"key"`
+	Object  json.RawMessage `json:"object"`
+}
+
+// FDv2DeleteObject is the data of a "delete-object" event.
+type FDv2DeleteObject struct {
+	Version int    `json:"version"`
+	Kind    string `json:"kind"`
+	Key     string `json:"key"`
+}
+
+// FDv2PayloadTransferred is the data of a "payload-transferred" event. State is the selector that
+// the SDK should send as its basis the next time it connects.
+type FDv2PayloadTransferred struct {
+	State   string `json:"state"`
+	Version int    `json:"version"`
+}
+
+// FDv2Goodbye is the data of a "goodbye" event.
+type FDv2Goodbye struct {
+	Reason string `json:"reason"`
+}
+
+// FDv2Change describes an update or deletion of a single flag or segment. If Object is nil, it is
+// a deletion.
+type FDv2Change struct {
+	Kind    DataItemKind
+	Key     string
+	Version int
+	Object  json.RawMessage
+}
+
+// FDv2Put is a shortcut for creating an FDv2Change that adds or replaces an item.
+func FDv2Put(kind DataItemKind, key string, version int, object json.RawMessage) FDv2Change {
+	return FDv2Change{Kind: kind, Key: key, Version: version, Object: object}
+}
+
+// FDv2Delete is a shortcut for creating an FDv2Change that deletes an item.
+func FDv2Delete(kind DataItemKind, key string, version int) FDv2Change {
+	return FDv2Change{Kind: kind, Key: key, Version: version}
+}
+
+type fdv2Changeset struct {
+	version int
+	changes []FDv2Change
+}
+
+// FDv2Payload is the versioned data state that is shared by FDv2StreamingService and
+// FDv2PollingService. Every set of changes that is applied to it produces a new payload version,
+// and it remembers the changesets so that an SDK which reconnects with an older selector can be
+// sent only what it missed.
+type FDv2Payload struct {
+	id         string
+	data       SDKData
+	version    int
+	changesets []fdv2Changeset
+	lock       sync.Mutex
+}
+
+// NewFDv2Payload creates an FDv2Payload with the specified ID and initial data. If data is not
+// ServerSDKData (for instance, if it is BlockingUnavailableSDKData), the payload is considered
+// unavailable and no events will be generated for it.
+func NewFDv2Payload(id string, data SDKData) *FDv2Payload {
+	return &FDv2Payload{id: id, data: data, version: 1}
+}
+
+// ID returns the payload ID.
+func (p *FDv2Payload) ID() string { return p.id }
+
+// Version returns the current payload version.
+func (p *FDv2Payload) Version() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.version
+}
+
+// Selector returns the selector state string for the current payload version. This is the value
+// that an up-to-date SDK would send in the basis parameter.
+func (p *FDv2Payload) Selector() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.selectorFor(p.version)
+}
+
+// Data returns the current data set.
+func (p *FDv2Payload) Data() SDKData {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.data
+}
+
+// SetData replaces the entire data set. This increments the payload version and discards the
+// change history, so any SDK that connects afterward will receive a full transfer.
+func (p *FDv2Payload) SetData(data SDKData) {
+	p.lock.Lock()
+	p.data = data
+	p.version++
+	p.changesets = nil
+	p.lock.Unlock()
+}
+
+// ApplyChanges updates the data set, increments the payload version, and returns the events that
+// should be sent to an SDK that was up to date with the previous version.
+func (p *FDv2Payload) ApplyChanges(changes ...FDv2Change) []FDv2Event {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	serverData, _ := p.data.(ServerSDKData)
+	newData := make(ServerSDKData)
+	for kind, items := range serverData {
+		newData[kind] = make(map[string]json.RawMessage)
+		for key, item := range items {
+			newData[kind][key] = item
+		}
+	}
+	for _, c := range changes {
+		if c.Object == nil {
+			delete(newData[c.Kind], c.Key)
+			continue
+		}
+		if newData[c.Kind] == nil {
+			newData[c.Kind] = make(map[string]json.RawMessage)
+		}
+		newData[c.Kind][c.Key] = c.Object
+	}
+	p.data = newData
+	p.version++
+	p.changesets = append(p.changesets, fdv2Changeset{version: p.version, changes: changes})
+
+	events := []FDv2Event{p.makeServerIntent(FDv2IntentTransferChanges, "stale")}
+	for _, c := range changes {
+		events = append(events, makeFDv2ChangeEvent(c))
+	}
+	return append(events, p.makePayloadTransferred())
+}
+
+// EventsForBasis returns the events that should be sent to an SDK that has connected with the
+// specified basis (an empty string if it did not provide one). If the basis matches the current
+// version, the result is just a "server-intent" with an intent code of "none". If it matches an
+// older version whose subsequent changes are all still known, the result contains only those
+// changes. Otherwise, it is a full transfer. If the payload is unavailable, it returns nil.
+func (p *FDv2Payload) EventsForBasis(basis string) []FDv2Event {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	serverData, ok := p.data.(ServerSDKData)
+	if !ok {
+		return nil
+	}
+
+	if basis != "" {
+		if basis == p.selectorFor(p.version) {
+			return []FDv2Event{p.makeServerIntent(FDv2IntentNone, "up-to-date")}
+		}
+		for i, cs := range p.changesets {
+			if basis != p.selectorFor(cs.version-1) {
+				continue
+			}
+			events := []FDv2Event{p.makeServerIntent(FDv2IntentTransferChanges, "stale")}
+			for _, later := range p.changesets[i:] {
+				for _, c := range later.changes {
+					events = append(events, makeFDv2ChangeEvent(c))
+				}
+			}
+			return append(events, p.makePayloadTransferred())
+		}
+	}
+
+	reason := helpers.IfElse(basis == "", "payload-missing", "cant-catchup")
+	events := []FDv2Event{p.makeServerIntent(FDv2IntentTransferFull, reason)}
+	kinds := make([]string, 0, len(serverData))
+	for kind := range serverData {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		items := serverData[DataItemKind(kind)]
+		keys := make([]string, 0, len(items))
+		for key := range items {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			events = append(events, makeFDv2ChangeEvent(FDv2Put(DataItemKind(kind), key,
+				getItemVersion(items[key]), items[key])))
+		}
+	}
+	return append(events, p.makePayloadTransferred())
+}
+
+func (p *FDv2Payload) selectorFor(version int) string {
+	return fmt.Sprintf("(p:%s:%d)", p.id, version)
+}
+
+func (p *FDv2Payload) makeServerIntent(code FDv2IntentCode, reason string) FDv2Event {
+	return makeFDv2Event(FDv2EventServerIntent, FDv2ServerIntent{
+		Payloads: []FDv2ServerIntentPayload{{ID: p.id, Target: p.version, IntentCode: code, Reason: reason}},
+	})
+}
+
+func (p *FDv2Payload) makePayloadTransferred() FDv2Event {
+	return makeFDv2Event(FDv2EventPayloadTransferred,
+		FDv2PayloadTransferred{State: p.selectorFor(p.version), Version: p.version})
+}
+
+func makeFDv2ChangeEvent(c FDv2Change) FDv2Event {
+	kind := fdv2ObjectKind(c.Kind)
+	if c.Object == nil {
+		return makeFDv2Event(FDv2EventDeleteObject, FDv2DeleteObject{Version: c.Version, Kind: kind, Key: c.Key})
+	}
+	return makeFDv2Event(FDv2EventPutObject,
+		FDv2PutObject{Version: c.Version, Kind: kind, Key: c.Key, Object: c.Object})
+}
+
+func makeFDv2Event(name string, data interface{}) FDv2Event {
+	bytes, _ := json.Marshal(data)
+	return FDv2Event{Name: name, Data: bytes}
+}
+
+// fdv2ObjectKind translates the namespace names used in ServerSDKData ("flags", "segments") into
+// the singular object kinds used in FDv2 events.
+func fdv2ObjectKind(kind DataItemKind) string {
+	switch kind {
+	case "flags":
+		return "flag"
+	case "segments":
+		return "segment"
+	default:
+		return string(kind)
+	}
+}
+
+func getItemVersion(item json.RawMessage) int {
+	var props struct {
+		Version int `json:"version"`
+	}
+	_ = json.Unmarshal(item, &props)
+	return props.Version
+}
@@ -0,0 +1,74 @@
+package mockld
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	PollingPathFDv2 = "/sdk/poll"
+)
+
+// FDv2PollingResponse is the body of a response from the FDv2 polling endpoint.
+type FDv2PollingResponse struct {
+	Events []FDv2Event `json:"events"`
+}
+
+// FDv2PollingService simulates the FDv2 polling service for server-side SDKs.
+//
+// Each request receives the events that FDv2Payload.EventsForBasis returns for the basis parameter
+// of the request, so an SDK that polls with its current selector gets only the changes it has not
+// yet seen. If the payload is unavailable, the service returns a 404 error.
+type FDv2PollingService struct {
+	payload     *FDv2Payload
+	handler     http.Handler
+	debugLogger framework.Logger
+}
+
+// NewFDv2PollingService creates an FDv2PollingService that serves the specified payload. The same
+// payload can be shared with an FDv2StreamingService.
+func NewFDv2PollingService(
+	payload *FDv2Payload,
+	debugLogger framework.Logger,
+) *FDv2PollingService {
+	p := &FDv2PollingService{
+		payload:     payload,
+		debugLogger: debugLogger,
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(PollingPathFDv2, p.pollHandler).Methods("GET")
+	p.handler = router
+
+	return p
+}
+
+func (p *FDv2PollingService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+// Payload returns the data state that this service is serving.
+func (p *FDv2PollingService) Payload() *FDv2Payload { return p.payload }
+
+func (p *FDv2PollingService) pollHandler(w http.ResponseWriter, r *http.Request) {
+	basis := r.URL.Query().Get(FDv2BasisQueryParam)
+	events := p.payload.EventsForBasis(basis)
+	if events == nil {
+		// This means we've deliberately configured the data source to be unavailable
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, _ := json.Marshal(FDv2PollingResponse{Events: events})
+	p.debugLogger.Printf("Sending poll data for basis %q: %s", basis, string(data))
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		p.debugLogger.Printf("failed to write polling body to writer: %v", err)
+	}
+}
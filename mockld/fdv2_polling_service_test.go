@@ -0,0 +1,88 @@
+package mockld
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldlog"
+	"github.com/launchdarkly/go-sdk-common/v3/ldlogtest"
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFDv2PollingService(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	testLog.Loggers.SetMinLevel(ldlog.Debug)
+	defer testLog.DumpIfTestFailed(t)
+
+	service := NewFDv2PollingService(NewFDv2Payload("payload1", makeFDv2TestData()), testLog.Loggers.ForLevel(ldlog.Debug))
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		t.Run("initialization", func(t *testing.T) {
+			events := doFDv2Poll(t, server, "")
+			require.Len(t, events, 4)
+			assert.Equal(t, FDv2EventServerIntent, events[0].Name)
+			assert.Equal(t, FDv2EventPutObject, events[1].Name)
+			assert.Equal(t, FDv2EventPutObject, events[2].Name)
+			assert.Equal(t, FDv2EventPayloadTransferred, events[3].Name)
+			assert.JSONEq(t, `{"state": "(p:payload1:1)", "version": 1}`, string(events[3].Data))
+		})
+
+		t.Run("up to date", func(t *testing.T) {
+			events := doFDv2Poll(t, server, service.Payload().Selector())
+			require.Len(t, events, 1)
+			assert.JSONEq(t,
+				`{"payloads": [{"id": "payload1", "target": 1, "intentCode": "none", "reason": "up-to-date"}]}`,
+				string(events[0].Data))
+		})
+
+		t.Run("incremental changes", func(t *testing.T) {
+			basis := service.Payload().Selector()
+			service.Payload().ApplyChanges(FDv2Delete("flags", "flag1", 2))
+
+			events := doFDv2Poll(t, server, basis)
+			require.Len(t, events, 3)
+			assert.Equal(t, FDv2EventServerIntent, events[0].Name)
+			assert.Equal(t, FDv2EventDeleteObject, events[1].Name)
+			assert.JSONEq(t, `{"version": 2, "kind": "flag", "key": "flag1"}`, string(events[1].Data))
+			assert.JSONEq(t, `{"state": "(p:payload1:2)", "version": 2}`, string(events[2].Data))
+		})
+	})
+}
+
+func TestFDv2PollingServiceUnavailable(t *testing.T) {
+	service := NewFDv2PollingService(NewFDv2Payload("payload1", BlockingUnavailableSDKData(ServerSideSDK)),
+		ldlogtest.NewMockLog().Loggers.ForLevel(ldlog.Debug))
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		req, _ := http.NewRequest("GET", server.URL+PollingPathFDv2, nil)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func doFDv2Poll(t *testing.T, server *httptest.Server, basis string) []FDv2Event {
+	pollURL := server.URL + PollingPathFDv2
+	if basis != "" {
+		pollURL += "?" + FDv2BasisQueryParam + "=" + url.QueryEscape(basis)
+	}
+	req, _ := http.NewRequest("GET", pollURL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var parsed FDv2PollingResponse
+	require.NoError(t, json.Unmarshal(body, &parsed))
+	return parsed.Events
+}
@@ -0,0 +1,189 @@
+package mockld
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+
+	"github.com/launchdarkly/eventsource"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	StreamingPathFDv2 = "/sdk/stream"
+)
+
+type fdv2BasisContextKey struct{}
+
+// FDv2StreamingService simulates the FDv2 streaming service for server-side SDKs.
+//
+// When an SDK connects, it receives the events that FDv2Payload.EventsForBasis returns for the
+// basis parameter of the request (if any). Subsequent changes are pushed to all connected clients.
+type FDv2StreamingService struct {
+	payload      *FDv2Payload
+	streams      *eventsource.Server
+	queuedEvents []eventsource.Event
+	started      bool
+	handler      http.Handler
+	debugLogger  framework.Logger
+	lock         sync.Mutex
+}
+
+// NewFDv2StreamingService creates an FDv2StreamingService that serves the specified payload. The
+// same payload can be shared with an FDv2PollingService.
+func NewFDv2StreamingService(
+	payload *FDv2Payload,
+	debugLogger framework.Logger,
+) *FDv2StreamingService {
+	streams := eventsource.NewServer()
+	streams.ReplayAll = true
+	streams.Logger = eventSourceDebugLogger{debugLogger}
+
+	s := &FDv2StreamingService{
+		payload:     payload,
+		streams:     streams,
+		debugLogger: debugLogger,
+	}
+
+	streamHandler := streams.Handler(allDataChannel)
+	router := mux.NewRouter()
+	router.HandleFunc(StreamingPathFDv2, func(w http.ResponseWriter, r *http.Request) {
+		// The eventsource server only tells the repository about the Last-Event-ID, so we pass
+		// the basis through the request context.
+		basis := r.URL.Query().Get(FDv2BasisQueryParam)
+		streamHandler(w, r.WithContext(context.WithValue(r.Context(), fdv2BasisContextKey{}, basis)))
+	}).Methods("GET")
+	s.handler = router
+
+	streams.Register(allDataChannel, s)
+
+	return s
+}
+
+func (s *FDv2StreamingService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Payload returns the data state that this service is serving.
+func (s *FDv2StreamingService) Payload() *FDv2Payload { return s.payload }
+
+// PushChanges applies a set of changes to the payload, and sends the resulting "server-intent",
+// "put-object"/"delete-object", and "payload-transferred" events to all connected clients. If no
+// client has connected yet, nothing is sent, since the first client will receive the updated
+// payload anyway.
+func (s *FDv2StreamingService) PushChanges(changes ...FDv2Change) {
+	s.pushPayloadEvents(func() []FDv2Event { return s.payload.ApplyChanges(changes...) })
+}
+
+// PushUpdate is a shortcut for PushChanges with a single FDv2Put.
+func (s *FDv2StreamingService) PushUpdate(kind DataItemKind, key string, version int, object []byte) {
+	s.PushChanges(FDv2Put(kind, key, version, object))
+}
+
+// PushDelete is a shortcut for PushChanges with a single FDv2Delete.
+func (s *FDv2StreamingService) PushDelete(kind DataItemKind, key string, version int) {
+	s.PushChanges(FDv2Delete(kind, key, version))
+}
+
+// PushFullTransfer replaces the payload data and sends the complete new data set to all connected
+// clients.
+func (s *FDv2StreamingService) PushFullTransfer(data SDKData) {
+	s.pushPayloadEvents(func() []FDv2Event {
+		s.payload.SetData(data)
+		return s.payload.EventsForBasis("")
+	})
+}
+
+// PushGoodbye sends a "goodbye" event, telling the SDK that the service is about to disconnect it.
+func (s *FDv2StreamingService) PushGoodbye(reason string) {
+	e := makeFDv2Event(FDv2EventGoodbye, FDv2Goodbye{Reason: reason})
+	s.PushEvent(e.Name, e.Data)
+}
+
+func (s *FDv2StreamingService) pushPayloadEvents(updateFn func() []FDv2Event) {
+	// Holding the lock here ensures that a connection which is starting at the same time will
+	// either see the updated payload in its initial events, or receive these events afterward.
+	s.lock.Lock()
+	events := updateFn()
+	alreadyStarted := s.started
+	s.lock.Unlock()
+
+	if !alreadyStarted {
+		s.debugLogger.Printf("Updated payload to version %d; will send it when a connection starts",
+			s.payload.Version())
+		return
+	}
+	for _, e := range events {
+		event := eventImpl{name: e.Name, data: e.Data}
+		s.logEvent(event)
+		s.streams.Publish([]string{allDataChannel}, event)
+	}
+}
+
+// PushEvent sends an SSE event to all clients that are currently connected to the stream-- or, if
+// no client has connected yet, queues it to be sent after the initial events to the first client
+// that connects. This has the same rationale as StreamingService.PushEvent.
+func (s *FDv2StreamingService) PushEvent(eventName string, eventData interface{}) {
+	event := eventImpl{
+		name: eventName,
+		data: eventData,
+	}
+
+	s.lock.Lock()
+	alreadyStarted := s.started
+	if !alreadyStarted {
+		s.queuedEvents = append(s.queuedEvents, event)
+	}
+	s.lock.Unlock()
+
+	if alreadyStarted {
+		s.logEvent(event)
+		s.streams.Publish([]string{allDataChannel}, event)
+	} else {
+		s.debugLogger.Printf("Will send %q event after connection has started", eventName)
+	}
+}
+
+func (s *FDv2StreamingService) Replay(channel, id string) chan eventsource.Event {
+	return s.replayEvents("")
+}
+
+func (s *FDv2StreamingService) ReplayWithContext(ctx context.Context, channel, id string) <-chan eventsource.Event {
+	basis, _ := ctx.Value(fdv2BasisContextKey{}).(string)
+	if basis != "" {
+		s.debugLogger.Printf("Stream request has basis %q", basis)
+	}
+	return s.replayEvents(basis)
+}
+
+func (s *FDv2StreamingService) replayEvents(basis string) chan eventsource.Event {
+	s.lock.Lock()
+	initialEvents := s.payload.EventsForBasis(basis)
+	queued := s.queuedEvents
+	if !s.started {
+		s.started = true
+		s.queuedEvents = nil
+	}
+	s.lock.Unlock()
+
+	eventsCh := make(chan eventsource.Event, len(initialEvents)+len(queued))
+	for _, e := range initialEvents {
+		ie := eventImpl{name: e.Name, data: e.Data}
+		s.logEvent(ie)
+		eventsCh <- ie
+	}
+	for _, qe := range queued {
+		s.logEvent(qe)
+		eventsCh <- qe
+	}
+
+	close(eventsCh)
+	return eventsCh
+}
+
+func (s *FDv2StreamingService) logEvent(e eventsource.Event) {
+	s.debugLogger.Printf("Sending %s event with data: %s", e.Event(), e.Data())
+}
@@ -0,0 +1,147 @@
+package mockld
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/launchdarkly/eventsource"
+	"github.com/launchdarkly/go-sdk-common/v3/ldlog"
+	"github.com/launchdarkly/go-sdk-common/v3/ldlogtest"
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeFDv2TestData() ServerSDKData {
+	return NewServerSDKDataBuilder().
+		RawFlag("flag1", json.RawMessage(`{"key": "flag1", "version": 1}`)).
+		RawSegment("segment1", json.RawMessage(`{"key": "segment1", "version": 2}`)).
+		Build()
+}
+
+func TestFDv2StreamingServiceInitialization(t *testing.T) {
+	withFDv2StreamingService(t, makeFDv2TestData(), func(service *FDv2StreamingService, server *httptest.Server) {
+		stream := subscribeFDv2Stream(t, server, "")
+		defer stream.Close()
+
+		requireFDv2ServerIntent(t, stream, FDv2IntentTransferFull, 1)
+		requireFDv2Event(t, stream, FDv2EventPutObject,
+			`{"version": 1, "kind": "flag", "key": "flag1", "object": {"key": "flag1", "version": 1}}`)
+		requireFDv2Event(t, stream, FDv2EventPutObject,
+			`{"version": 2, "kind": "segment", "key": "segment1", "object": {"key": "segment1", "version": 2}}`)
+		requireFDv2Event(t, stream, FDv2EventPayloadTransferred, `{"state": "(p:payload1:1)", "version": 1}`)
+	})
+}
+
+func TestFDv2StreamingServiceIncrementalChanges(t *testing.T) {
+	withFDv2StreamingService(t, makeFDv2TestData(), func(service *FDv2StreamingService, server *httptest.Server) {
+		stream := subscribeFDv2Stream(t, server, "")
+		defer stream.Close()
+
+		requireFDv2Event(t, stream, FDv2EventServerIntent, "")
+		requireFDv2Event(t, stream, FDv2EventPutObject, "")
+		requireFDv2Event(t, stream, FDv2EventPutObject, "")
+		requireFDv2Event(t, stream, FDv2EventPayloadTransferred, "")
+
+		go service.PushChanges(
+			FDv2Put("flags", "flag2", 3, json.RawMessage(`{"key": "flag2", "version": 3}`)),
+			FDv2Delete("segments", "segment1", 4),
+		)
+
+		requireFDv2ServerIntent(t, stream, FDv2IntentTransferChanges, 2)
+		requireFDv2Event(t, stream, FDv2EventPutObject,
+			`{"version": 3, "kind": "flag", "key": "flag2", "object": {"key": "flag2", "version": 3}}`)
+		requireFDv2Event(t, stream, FDv2EventDeleteObject, `{"version": 4, "kind": "segment", "key": "segment1"}`)
+		requireFDv2Event(t, stream, FDv2EventPayloadTransferred, `{"state": "(p:payload1:2)", "version": 2}`)
+
+		go service.PushGoodbye("maintenance")
+		requireFDv2Event(t, stream, FDv2EventGoodbye, `{"reason": "maintenance"}`)
+	})
+}
+
+func TestFDv2StreamingServiceSelectorResumption(t *testing.T) {
+	t.Run("basis is current", func(t *testing.T) {
+		withFDv2StreamingService(t, makeFDv2TestData(), func(service *FDv2StreamingService, server *httptest.Server) {
+			stream := subscribeFDv2Stream(t, server, "(p:payload1:1)")
+			defer stream.Close()
+
+			requireFDv2ServerIntent(t, stream, FDv2IntentNone, 1)
+		})
+	})
+
+	t.Run("basis is older", func(t *testing.T) {
+		withFDv2StreamingService(t, makeFDv2TestData(), func(service *FDv2StreamingService, server *httptest.Server) {
+			service.Payload().ApplyChanges(FDv2Delete("flags", "flag1", 2))
+			service.Payload().ApplyChanges(FDv2Put("flags", "flag2", 1, json.RawMessage(`{"key": "flag2"}`)))
+
+			stream := subscribeFDv2Stream(t, server, "(p:payload1:2)")
+			defer stream.Close()
+
+			requireFDv2ServerIntent(t, stream, FDv2IntentTransferChanges, 3)
+			requireFDv2Event(t, stream, FDv2EventPutObject,
+				`{"version": 1, "kind": "flag", "key": "flag2", "object": {"key": "flag2"}}`)
+			requireFDv2Event(t, stream, FDv2EventPayloadTransferred, `{"state": "(p:payload1:3)", "version": 3}`)
+		})
+	})
+
+	t.Run("basis is unknown", func(t *testing.T) {
+		withFDv2StreamingService(t, makeFDv2TestData(), func(service *FDv2StreamingService, server *httptest.Server) {
+			stream := subscribeFDv2Stream(t, server, "(p:otherpayload:1)")
+			defer stream.Close()
+
+			requireFDv2ServerIntent(t, stream, FDv2IntentTransferFull, 1)
+		})
+	})
+}
+
+func withFDv2StreamingService(
+	t *testing.T,
+	data SDKData,
+	action func(*FDv2StreamingService, *httptest.Server),
+) {
+	testLog := ldlogtest.NewMockLog()
+	testLog.Loggers.SetMinLevel(ldlog.Debug)
+	defer testLog.DumpIfTestFailed(t)
+
+	service := NewFDv2StreamingService(NewFDv2Payload("payload1", data), testLog.Loggers.ForLevel(ldlog.Debug))
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		action(service, server)
+	})
+}
+
+func subscribeFDv2Stream(t *testing.T, server *httptest.Server, basis string) *eventsource.Stream {
+	streamURL := server.URL + StreamingPathFDv2
+	if basis != "" {
+		streamURL += "?" + FDv2BasisQueryParam + "=" + url.QueryEscape(basis)
+	}
+	req, _ := http.NewRequest("GET", streamURL, nil)
+	stream, err := eventsource.SubscribeWithRequest("", req)
+	require.NoError(t, err)
+	return stream
+}
+
+func requireFDv2ServerIntent(t *testing.T, stream *eventsource.Stream, code FDv2IntentCode, target int) {
+	e := requireEvent(t, stream)
+	require.Equal(t, FDv2EventServerIntent, e.Event())
+	var intent FDv2ServerIntent
+	require.NoError(t, json.Unmarshal([]byte(e.Data()), &intent))
+	require.Len(t, intent.Payloads, 1)
+	assert.Equal(t, "payload1", intent.Payloads[0].ID)
+	assert.Equal(t, code, intent.Payloads[0].IntentCode)
+	assert.Equal(t, target, intent.Payloads[0].Target)
+}
+
+// requireFDv2Event waits for an event with the specified name; if expectedData is non-empty, it also
+// verifies the event data.
+func requireFDv2Event(t *testing.T, stream *eventsource.Stream, name, expectedData string) {
+	e := requireEvent(t, stream)
+	require.Equal(t, name, e.Event())
+	if expectedData != "" {
+		m.In(t).Assert(e.Data(), m.JSONStrEqual(expectedData))
+	}
+}
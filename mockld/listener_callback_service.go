@@ -0,0 +1,66 @@
+package mockld
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
+)
+
+// ListenerCallbackService is an HTTP endpoint that records the callbacks the test service posts
+// when an SDK listener, such as a flag change listener or a data source status listener, is
+// notified. T is the type of the JSON callback payload.
+type ListenerCallbackService[T any] struct {
+	payloadEndpoint *harness.MockEndpoint
+	CallChannel     chan T
+}
+
+func (l *ListenerCallbackService[T]) GetURL() string {
+	return l.payloadEndpoint.BaseURL()
+}
+
+func (l *ListenerCallbackService[T]) Close() {
+	l.payloadEndpoint.Close()
+}
+
+// NewListenerCallbackService creates an HTTP endpoint that records incoming listener callbacks.
+// The description is used in log output to identify the kind of listener.
+func NewListenerCallbackService[T any](
+	testHarness *harness.TestHarness,
+	logger framework.Logger,
+	description string,
+) *ListenerCallbackService[T] {
+	l := &ListenerCallbackService[T]{
+		CallChannel: make(chan T, 100),
+	}
+
+	endpointHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bytes, err := io.ReadAll(req.Body)
+		logger.Printf("Received from %s: %s", description, string(bytes))
+		if err != nil {
+			logger.Printf("Could not read body from %s.", description)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var payload T
+		if err := json.Unmarshal(bytes, &payload); err != nil {
+			logger.Printf("Could not unmarshal %s payload.", description)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Unlike hook callbacks, listener notifications happen asynchronously in the SDK and tests
+		// care about their order, so we queue them in a buffered channel instead of sending each
+		// one from its own goroutine.
+		l.CallChannel <- payload
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	l.payloadEndpoint = testHarness.NewMockEndpoint(
+		endpointHandler, logger, harness.MockEndpointDescription(description))
+
+	return l
+}
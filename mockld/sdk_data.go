@@ -267,3 +267,34 @@ func (b *ClientSDKDataBuilder) FlagWithValue(
 ) *ClientSDKDataBuilder {
 	return b.Flag(key, ClientSDKFlag{Version: version, Value: value, Variation: o.Some(variationIndex)})
 }
+
+type bootstrapFlagMetadata struct {
+	Variation            *int                        `json:"variation,omitempty"`
+	Version              int                         `json:"version"`
+	TrackEvents          bool                        `json:"trackEvents,omitempty"`
+	TrackReason          bool                        `json:"trackReason,omitempty"`
+	DebugEventsUntilDate *ldtime.UnixMillisecondTime `json:"debugEventsUntilDate,omitempty"`
+	Reason               *ldreason.EvaluationReason  `json:"reason,omitempty"`
+}
+
+// BootstrapJSON returns the same data in the format that a server-side SDK's allFlagsState method
+// produces, which a JS-based client-side SDK can use to bootstrap its flag data without connecting to
+// LaunchDarkly. That is an object where each flag key maps to the flag value, with additional metadata
+// for each flag in a "$flagsState" property.
+func (d ClientSDKData) BootstrapJSON() json.RawMessage {
+	ret := map[string]any{"$valid": true}
+	flagsState := make(map[string]bootstrapFlagMetadata)
+	for key, flag := range d {
+		ret[key] = flag.Value
+		flagsState[key] = bootstrapFlagMetadata{
+			Variation:            flag.Variation.AsPtr(),
+			Version:              flag.FlagVersion.OrElse(flag.Version),
+			TrackEvents:          flag.TrackEvents,
+			TrackReason:          flag.TrackReason,
+			DebugEventsUntilDate: flag.DebugEventsUntilDate.AsPtr(),
+			Reason:               flag.Reason.AsPtr(),
+		}
+	}
+	ret["$flagsState"] = flagsState
+	return jsonhelpers.ToJSON(ret)
+}
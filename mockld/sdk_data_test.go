@@ -0,0 +1,41 @@
+package mockld
+
+import (
+	"testing"
+
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldreason"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientSDKDataBootstrapJSON(t *testing.T) {
+	data := NewClientSDKDataBuilder().
+		Flag("flag1", ClientSDKFlag{
+			Value:       ldvalue.String("a"),
+			Variation:   o.Some(1),
+			Version:     100,
+			FlagVersion: o.Some(10),
+			TrackEvents: true,
+			Reason:      o.Some(ldreason.NewEvalReasonFallthrough()),
+		}).
+		Flag("flag2", ClientSDKFlag{
+			Value:                ldvalue.Bool(true),
+			Version:              20,
+			DebugEventsUntilDate: o.Some(ldtime.UnixMillisecondTime(1000)),
+		}).
+		Build()
+
+	assert.JSONEq(t, `{
+		"flag1": "a",
+		"flag2": true,
+		"$flagsState": {
+			"flag1": {"variation": 1, "version": 10, "trackEvents": true, "reason": {"kind": "FALLTHROUGH"}},
+			"flag2": {"version": 20, "debugEventsUntilDate": 1000}
+		},
+		"$valid": true
+	}`, string(data.BootstrapJSON()))
+}
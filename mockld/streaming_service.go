@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/launchdarkly/sdk-test-harness/v2/framework"
 
@@ -29,6 +31,10 @@ const errClientSideStreamCanOnlyUseFlags = `A client-side test attempted to refe
 	` "flags" in the mock streaming service.` +
 	` This is a test logic error, since client-side streams have nowhere to put any data other than flag data.`
 
+const errServerSideStreamCannotPing = `A server-side test attempted to send a "ping" event` +
+	` in the mock streaming service.` +
+	` This is a test logic error, since only client-side SDKs support that event.`
+
 type eventSourceDebugLogger struct {
 	logger framework.Logger
 }
@@ -38,21 +44,29 @@ func (l eventSourceDebugLogger) Println(args ...interface{}) {
 }
 
 func (l eventSourceDebugLogger) Printf(fmt string, args ...interface{}) {
-	l.logger.Printf(fmt, args)
+	l.logger.Printf(fmt, args...)
 }
 
 type StreamingService struct {
-	sdkKind      SDKKind
-	initialData  SDKData
-	streams      *eventsource.Server
-	queuedEvents []eventsource.Event
-	started      bool
-	handler      http.Handler
-	debugLogger  framework.Logger
-	lock         sync.RWMutex
+	sdkKind         SDKKind
+	initialData     SDKData
+	streams         *eventsource.Server
+	queuedEvents    []eventsource.Event
+	started         bool
+	handler         http.Handler
+	debugLogger     framework.Logger
+	eventIDs        bool
+	partialReplay   bool
+	lastEventNumber int
+	sentEvents      []eventImpl
+	lastEventIDs    []string
+	stallCount      int
+	stopHeartbeat   chan struct{}
+	lock            sync.RWMutex
 }
 
 type eventImpl struct {
+	id   string
 	name string
 	data interface{}
 }
@@ -77,7 +91,7 @@ func NewStreamingService(
 		debugLogger: debugLogger,
 	}
 
-	streamHandler := streams.Handler(allDataChannel)
+	streamHandler := s.stallableHandler(streams.Handler(allDataChannel))
 	router := mux.NewRouter()
 	switch sdkKind {
 	case ServerSideSDK:
@@ -102,6 +116,117 @@ func NewStreamingService(
 	return s
 }
 
+// WithEventIDs enables or disables event IDs. If enabled, every event that the service sends has an
+// "id" field, which is a number that increases by one for each event. An SDK that is following the SSE
+// specification will send the ID of the last event it received in the Last-Event-ID header when it
+// reconnects.
+func (s *StreamingService) WithEventIDs(enable bool) *StreamingService {
+	s.lock.Lock()
+	s.eventIDs = enable
+	s.lock.Unlock()
+	return s
+}
+
+// WithPartialReplay enables or disables partial replay, which also enables event IDs. If enabled, and
+// an SDK reconnects with a Last-Event-ID header that matches the ID of an event that was previously
+// sent, the service sends only the events that were sent after that one, instead of the full data set.
+// If the header is missing or doesn't match any event, the SDK receives the full data set as usual.
+func (s *StreamingService) WithPartialReplay(enable bool) *StreamingService {
+	s.lock.Lock()
+	s.partialReplay = enable
+	s.eventIDs = s.eventIDs || enable
+	s.lock.Unlock()
+	return s
+}
+
+// LastEventIDs returns the value of the Last-Event-ID header for each stream connection that has been
+// made so far, in the order they were made. The value is an empty string if there was no header.
+func (s *StreamingService) LastEventIDs() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string(nil), s.lastEventIDs...)
+}
+
+// StartHeartbeat causes the service to send an SSE comment line to all connected clients at the specified
+// interval, the way LaunchDarkly does to keep an idle stream alive, until StopHeartbeat is called. If
+// heartbeats were already started, this replaces the previous interval.
+func (s *StreamingService) StartHeartbeat(interval time.Duration) {
+	s.StopHeartbeat()
+	stop := make(chan struct{})
+	s.lock.Lock()
+	s.stopHeartbeat = stop
+	s.lock.Unlock()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.streams.PublishComment([]string{allDataChannel}, "")
+			}
+		}
+	}()
+}
+
+// StopHeartbeat stops sending the comments that were started by StartHeartbeat, if any.
+func (s *StreamingService) StopHeartbeat() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopHeartbeat != nil {
+		close(s.stopHeartbeat)
+		s.stopHeartbeat = nil
+	}
+}
+
+// StallActiveConnections causes every stream connection that is currently open to go silent without
+// being closed: nothing else, not even a heartbeat comment, will be written to it. This simulates a
+// network problem that the SDK can only detect by way of its read timeout. Connections that are made
+// after this call are not affected.
+func (s *StreamingService) StallActiveConnections() {
+	s.lock.Lock()
+	s.stallCount++
+	s.lock.Unlock()
+	s.debugLogger.Printf("Stalling all active stream connections")
+}
+
+func (s *StreamingService) stallableHandler(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.lock.RLock()
+		stallCount := s.stallCount
+		s.lock.RUnlock()
+		h(&stallableResponseWriter{ResponseWriter: w, service: s, stallCount: stallCount}, r)
+	}
+}
+
+// stallableResponseWriter discards all output once StreamingService.StallActiveConnections has been
+// called after the connection was made.
+type stallableResponseWriter struct {
+	http.ResponseWriter
+	service    *StreamingService
+	stallCount int
+}
+
+func (w *stallableResponseWriter) stalled() bool {
+	w.service.lock.RLock()
+	defer w.service.lock.RUnlock()
+	return w.service.stallCount > w.stallCount
+}
+
+func (w *stallableResponseWriter) Write(data []byte) (int, error) {
+	if w.stalled() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *stallableResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && !w.stalled() {
+		flusher.Flush()
+	}
+}
+
 func (s *StreamingService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.handler.ServeHTTP(w, r)
 }
@@ -113,14 +238,12 @@ func (s *StreamingService) SetInitialData(data SDKData) {
 }
 
 func (s *StreamingService) RefreshAll() {
-	event := s.makePutEvent()
-	if event != nil {
-		s.logEvent(event)
-		s.streams.Publish([]string{allDataChannel}, event)
+	if event := s.makePutEvent(); event != nil {
+		s.publish(*event)
 	}
 }
 
-func (s *StreamingService) makePutEvent() eventsource.Event {
+func (s *StreamingService) makePutEvent() *eventImpl {
 	s.lock.RLock()
 	var data []byte
 	if s.initialData == nil {
@@ -141,7 +264,7 @@ func (s *StreamingService) makePutEvent() eventsource.Event {
 		}
 	}
 
-	return eventImpl{
+	return &eventImpl{
 		name: "put",
 		data: eventData,
 	}
@@ -167,8 +290,7 @@ func (s *StreamingService) PushEvent(eventName string, eventData interface{}) {
 	s.lock.Unlock()
 
 	if alreadyStarted {
-		s.logEvent(event)
-		s.streams.Publish([]string{allDataChannel}, event)
+		s.publish(event)
 	} else {
 		s.debugLogger.Printf("Will send %q event after connection has started", eventName)
 	}
@@ -209,43 +331,103 @@ func (s *StreamingService) PushDelete(namespace, key string, version int) {
 	s.PushEvent("delete", eventData)
 }
 
-func (s *StreamingService) Replay(channel, id string) chan eventsource.Event {
-	e := s.makePutEvent()
+// PushPing sends a "ping" event, which tells a client-side SDK to request the latest flag data from the
+// polling endpoint. This is only valid for client-side SDKs.
+func (s *StreamingService) PushPing() {
+	if s.sdkKind.IsServerSide() {
+		panic(errServerSideStreamCannotPing)
+	}
+	s.PushEvent("ping", json.RawMessage(""))
+}
 
+func (s *StreamingService) Replay(channel, id string) chan eventsource.Event {
 	// The use of a channel here is just part of how the eventsource server API works-- the Replay
 	// method is expected to return a channel, which could be either pre-populated or pushed to
 	// by another goroutine. In this case we're just pre-populating it with the same initial data
 	// that we provide to every incoming connection, plus any events that were queued by test logic
-	// before the connection actually started.
+	// before the connection actually started. The id parameter is the Last-Event-ID header, if any;
+	// if partial replay is enabled, we may send only the events after that one instead.
 
 	s.lock.Lock()
+	s.lastEventIDs = append(s.lastEventIDs, id)
+	if missed, ok := s.eventsSentAfter(id); ok {
+		s.lock.Unlock()
+		s.debugLogger.Printf("Replaying %d event(s) sent after event ID %q", len(missed), id)
+		eventsCh := make(chan eventsource.Event, len(missed))
+		for _, e := range missed {
+			s.logEvent(e)
+			eventsCh <- e
+		}
+		close(eventsCh)
+		return eventsCh
+	}
 	queued := s.queuedEvents
-	eventsCh := make(chan eventsource.Event, len(queued)+1)
 	if !s.started {
 		s.started = true
 		s.queuedEvents = nil
 	}
 	s.lock.Unlock()
 
-	if e != nil {
-		s.logEvent(e)
-		eventsCh <- e
+	var events []eventsource.Event
+	if e := s.makePutEvent(); e != nil {
+		events = append(events, s.assignID(*e))
 	}
 	for _, qe := range queued {
-		s.logEvent(qe)
-		eventsCh <- qe
+		events = append(events, s.assignID(qe.(eventImpl)))
 	}
 
+	eventsCh := make(chan eventsource.Event, len(events))
+	for _, e := range events {
+		s.logEvent(e)
+		eventsCh <- e
+	}
 	close(eventsCh)
 	return eventsCh
 }
 
+// eventsSentAfter returns the events that were sent after the one with the specified ID, if partial
+// replay is enabled and there was such an event. The caller must hold the lock.
+func (s *StreamingService) eventsSentAfter(id string) ([]eventsource.Event, bool) {
+	if !s.partialReplay || id == "" {
+		return nil, false
+	}
+	for i, e := range s.sentEvents {
+		if e.id == id {
+			var ret []eventsource.Event
+			for _, missed := range s.sentEvents[i+1:] {
+				ret = append(ret, missed)
+			}
+			return ret, true
+		}
+	}
+	return nil, false
+}
+
+// assignID gives the event the next event ID, if event IDs are enabled, and remembers it in case we
+// need to replay it later.
+func (s *StreamingService) assignID(e eventImpl) eventImpl {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.eventIDs {
+		s.lastEventNumber++
+		e.id = strconv.Itoa(s.lastEventNumber)
+		s.sentEvents = append(s.sentEvents, e)
+	}
+	return e
+}
+
+func (s *StreamingService) publish(e eventImpl) {
+	event := s.assignID(e)
+	s.logEvent(event)
+	s.streams.Publish([]string{allDataChannel}, event)
+}
+
 func (s *StreamingService) logEvent(e eventsource.Event) {
 	s.debugLogger.Printf("Sending %s event with data: %s", e.Event(), e.Data())
 }
 
 func (e eventImpl) Event() string { return e.name }
-func (e eventImpl) Id() string    { return "" } //nolint:revive // required by interface
+func (e eventImpl) Id() string    { return e.id } //nolint:revive // required by interface
 func (e eventImpl) Data() string {
 	if raw, ok := e.data.(json.RawMessage); ok {
 		return string(raw) // this allows us to pass malformed data that json.Marshal wouldn't allow
@@ -1,6 +1,7 @@
 package mockld
 
 import (
+	"bufio"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -112,3 +113,165 @@ func expectedServerSidePutData(sdkData SDKData) string {
 func expectedClientSidePutData(sdkData SDKData) string {
 	return string(sdkData.Serialize())
 }
+
+func TestStreamingServicePing(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	defer testLog.DumpIfTestFailed(t)
+	service := NewStreamingService(EmptyClientSDKData(), MobileSDK, testLog.Loggers.ForLevel(ldlog.Debug))
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		req, _ := http.NewRequest("GET", server.URL+"/meval/fakeuserdata", nil)
+		stream, err := eventsource.SubscribeWithRequest("", req)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		assert.Equal(t, "put", requireEvent(t, stream).Event())
+
+		go service.PushPing()
+
+		pingEvent := requireEvent(t, stream)
+		assert.Equal(t, "ping", pingEvent.Event())
+		assert.Equal(t, "", pingEvent.Data())
+	})
+}
+
+func TestStreamingServicePingIsNotAllowedForServerSide(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	service := NewStreamingService(EmptyServerSDKData(), ServerSideSDK, testLog.Loggers.ForLevel(ldlog.Debug))
+	assert.Panics(t, service.PushPing)
+}
+
+func TestStreamingServiceEventIDs(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	defer testLog.DumpIfTestFailed(t)
+	service := NewStreamingService(EmptyClientSDKData(), MobileSDK, testLog.Loggers.ForLevel(ldlog.Debug)).
+		WithEventIDs(true)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		stream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "")
+		defer stream.Close()
+
+		initialEvent := requireEvent(t, stream)
+		assert.Equal(t, "put", initialEvent.Event())
+		assert.Equal(t, "1", initialEvent.Id())
+
+		go service.PushDelete("flags", "flag1", 2)
+		assert.Equal(t, "2", requireEvent(t, stream).Id())
+
+		stream.Close()
+		stream = subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "2")
+		defer stream.Close()
+
+		// without partial replay, the SDK gets the full data set again
+		reconnectEvent := requireEvent(t, stream)
+		assert.Equal(t, "put", reconnectEvent.Event())
+		assert.Equal(t, "3", reconnectEvent.Id())
+
+		assert.Equal(t, []string{"", "2"}, service.LastEventIDs())
+	})
+}
+
+func TestStreamingServicePartialReplay(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	defer testLog.DumpIfTestFailed(t)
+	service := NewStreamingService(EmptyClientSDKData(), MobileSDK, testLog.Loggers.ForLevel(ldlog.Debug)).
+		WithPartialReplay(true)
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		stream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "")
+		assert.Equal(t, "1", requireEvent(t, stream).Id())
+
+		go func() {
+			service.PushDelete("flags", "flag1", 2)
+			service.PushDelete("flags", "flag2", 3)
+			service.PushDelete("flags", "flag3", 4)
+		}()
+		for _, expectedID := range []string{"2", "3", "4"} {
+			assert.Equal(t, expectedID, requireEvent(t, stream).Id())
+		}
+		stream.Close()
+
+		t.Run("known event ID", func(t *testing.T) {
+			stream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "2")
+			defer stream.Close()
+			for _, expectedID := range []string{"3", "4"} {
+				e := requireEvent(t, stream)
+				assert.Equal(t, "delete", e.Event())
+				assert.Equal(t, expectedID, e.Id())
+			}
+		})
+
+		t.Run("unknown event ID", func(t *testing.T) {
+			stream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "999")
+			defer stream.Close()
+			e := requireEvent(t, stream)
+			assert.Equal(t, "put", e.Event())
+			assert.Equal(t, "5", e.Id())
+		})
+	})
+}
+
+func subscribeWithLastEventID(t *testing.T, url, lastEventID string) *eventsource.Stream {
+	req, _ := http.NewRequest("GET", url, nil)
+	stream, err := eventsource.SubscribeWithRequest(lastEventID, req)
+	require.NoError(t, err)
+	return stream
+}
+
+func TestStreamingServiceHeartbeat(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	defer testLog.DumpIfTestFailed(t)
+	service := NewStreamingService(EmptyClientSDKData(), MobileSDK, testLog.Loggers.ForLevel(ldlog.Debug))
+	service.StartHeartbeat(time.Millisecond * 10)
+	defer service.StopHeartbeat()
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		resp, err := http.Get(server.URL + "/meval/fakeuserdata")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		lines := make(chan string, 100)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			close(lines)
+		}()
+		deadline := time.After(time.Second * 5)
+		for {
+			select {
+			case line := <-lines:
+				if line == ":" {
+					return
+				}
+			case <-deadline:
+				require.Fail(t, "timed out waiting for heartbeat comment")
+			}
+		}
+	})
+}
+
+func TestStreamingServiceStallActiveConnections(t *testing.T) {
+	testLog := ldlogtest.NewMockLog()
+	defer testLog.DumpIfTestFailed(t)
+	service := NewStreamingService(EmptyClientSDKData(), MobileSDK, testLog.Loggers.ForLevel(ldlog.Debug))
+
+	httphelpers.WithServer(service, func(server *httptest.Server) {
+		stalledStream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "")
+		defer stalledStream.Close()
+		assert.Equal(t, "put", requireEvent(t, stalledStream).Event())
+
+		service.StallActiveConnections()
+		service.PushDelete("flags", "flag1", 2)
+		h.RequireNoMoreValues(t, stalledStream.Events, time.Millisecond*100)
+
+		newStream := subscribeWithLastEventID(t, server.URL+"/meval/fakeuserdata", "")
+		defer newStream.Close()
+		assert.Equal(t, "put", requireEvent(t, newStream).Event())
+
+		go service.PushDelete("flags", "flag2", 3)
+		assert.Equal(t, "delete", requireEvent(t, newStream).Event())
+		h.RequireNoMoreValues(t, stalledStream.Events, time.Millisecond*100)
+	})
+}
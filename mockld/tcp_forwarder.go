@@ -0,0 +1,129 @@
+package mockld
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const tcpForwarderDialTimeout = time.Second * 5
+
+// TCPForwarder is a simple TCP proxy that forwards every connection it receives to a target address.
+// It can be made unavailable, to simulate an outage of a service that the SDK connects to directly
+// rather than through HTTP, such as a database.
+type TCPForwarder struct {
+	listener  net.Listener
+	target    string
+	available bool
+	conns     map[net.Conn]struct{}
+	lock      sync.Mutex
+}
+
+// NewTCPForwarder starts a TCPForwarder that listens on an arbitrary local port and forwards
+// connections to the specified "host:port" address.
+func NewTCPForwarder(target string) (*TCPForwarder, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f := &TCPForwarder{
+		listener:  listener,
+		target:    target,
+		available: true,
+		conns:     make(map[net.Conn]struct{}),
+	}
+	go f.acceptConnections()
+	return f, nil
+}
+
+// Addr returns the "host:port" address that the forwarder is listening on.
+func (f *TCPForwarder) Addr() string {
+	return f.listener.Addr().String()
+}
+
+// SetAvailable determines whether connections are forwarded. Making the forwarder unavailable closes
+// all of its active connections, and any new connection will be closed as soon as it is accepted,
+// until the forwarder is made available again.
+func (f *TCPForwarder) SetAvailable(available bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.available = available
+	if !available {
+		for conn := range f.conns {
+			_ = conn.Close()
+		}
+		clear(f.conns)
+	}
+}
+
+// Close stops the forwarder and closes all of its active connections.
+func (f *TCPForwarder) Close() {
+	_ = f.listener.Close()
+	f.SetAvailable(false)
+}
+
+func (f *TCPForwarder) acceptConnections() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return // the listener was closed
+		}
+		go f.forward(conn)
+	}
+}
+
+func (f *TCPForwarder) forward(conn net.Conn) {
+	if !f.isAvailable() {
+		_ = conn.Close()
+		return
+	}
+	targetConn, err := net.DialTimeout("tcp", f.target, tcpForwarderDialTimeout)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	if !f.track(conn, targetConn) {
+		_ = conn.Close()
+		_ = targetConn.Close()
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(targetConn, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, targetConn)
+		done <- struct{}{}
+	}()
+	<-done // as soon as either side is closed, we close both
+
+	f.lock.Lock()
+	delete(f.conns, conn)
+	delete(f.conns, targetConn)
+	f.lock.Unlock()
+	_ = conn.Close()
+	_ = targetConn.Close()
+}
+
+func (f *TCPForwarder) isAvailable() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.available
+}
+
+// track records the connections so they can be closed by SetAvailable, unless the forwarder became
+// unavailable while we were connecting to the target.
+func (f *TCPForwarder) track(conns ...net.Conn) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.available {
+		return false
+	}
+	for _, conn := range conns {
+		f.conns[conn] = struct{}{}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package mockld
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return listener
+}
+
+func requireEcho(t *testing.T, conn net.Conn, line string) {
+	_, err := conn.Write([]byte(line + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	received, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, line+"\n", received)
+}
+
+func assertClosed(t *testing.T, conn net.Conn) {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err := conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "connection was not closed")
+}
+
+func TestTCPForwarderForwardsConnections(t *testing.T) {
+	echo := startEchoServer(t)
+	f, err := NewTCPForwarder(echo.Addr().String())
+	require.NoError(t, err)
+	defer f.Close()
+
+	conn, err := net.Dial("tcp", f.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+	requireEcho(t, conn, "hello")
+}
+
+func TestTCPForwarderUnavailable(t *testing.T) {
+	echo := startEchoServer(t)
+	f, err := NewTCPForwarder(echo.Addr().String())
+	require.NoError(t, err)
+	defer f.Close()
+
+	conn1, err := net.Dial("tcp", f.Addr())
+	require.NoError(t, err)
+	defer conn1.Close()
+	requireEcho(t, conn1, "hello")
+
+	f.SetAvailable(false)
+	assertClosed(t, conn1)
+
+	conn2, err := net.Dial("tcp", f.Addr())
+	require.NoError(t, err)
+	defer conn2.Close()
+	assertClosed(t, conn2)
+
+	f.SetAvailable(true)
+	conn3, err := net.Dial("tcp", f.Addr())
+	require.NoError(t, err)
+	defer conn3.Close()
+	requireEcho(t, conn3, "hello again")
+}
@@ -3,35 +3,57 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
 )
 
+// defaultTestTimeout is long enough for any test that doesn't set its own time limit, so that a test
+// only times out if something is stuck.
+const defaultTestTimeout = time.Minute * 5
+
 type commandParams struct {
 	serviceURL             string
+	serviceCommand         string
 	port                   int
 	host                   string
 	filters                ldtest.RegexFilters
+	shard                  ldtest.Shard
 	stopServiceAtEnd       bool
 	debug                  bool
 	debugAll               bool
 	enablePersistenceTests bool
 	enableLongRunningTests bool
 	jUnitFile              string
+	jsonResultsFile        string
+	capabilityReportFile   string
 	recordFailures         string
 	skipFile               string
 	queryTimeoutSeconds    int
+	commandTimeoutSeconds  int
+	testTimeoutSeconds     int
+	retryFailures          int
+	failOnFlaky            bool
+	parallel               int
+	list                   bool
+	listCapabilities       string
+	listStatusFile         string
 }
 
 func (c *commandParams) Read(args []string) bool {
 	fs := flag.NewFlagSet("", flag.ExitOnError)
 	fs.StringVar(&c.serviceURL, "url", "", "test service URL")
+	fs.StringVar(&c.serviceCommand, "service-cmd", "", "shell command to start the test service; the test "+
+		"harness will stop it at the end, and restart it if it exits during the run (requires -url)")
 	fs.StringVar(&c.host, "host", "localhost", "external hostname of the test harness")
 	fs.IntVar(&c.port, "port", defaultPort, "http port that the test harness will listen on"+
 		" (if TLS capability enabled in an SDK, then port+1 will be used for HTTPS)")
 	fs.Var(&c.filters.MustMatch, "run", "regex pattern(s) to select tests to run")
 	fs.Var(&c.filters.MustNotMatch, "skip", "regex pattern(s) to select tests not to run")
+	shard := fs.String("shard", "", `run only one part of the test suite, in the format "index/count", `+
+		`such as "2/4" for the second of four parts`)
 	fs.BoolVar(&c.stopServiceAtEnd, "stop-service-at-end", false, "tell test service to exit after the test run")
 	fs.BoolVar(&c.debug, "debug", false, "enable debug logging for failed tests")
 	fs.BoolVar(&c.debugAll, "debug-all", false, "enable debug logging for all tests")
@@ -40,18 +62,68 @@ func (c *commandParams) Read(args []string) bool {
 	fs.BoolVar(&c.enableLongRunningTests, "enable-long-running-tests", false,
 		"enable tests that take a long time to run (10+ seconds)")
 	fs.StringVar(&c.jUnitFile, "junit", "", "write JUnit XML output to the specified path")
+	fs.StringVar(&c.jsonResultsFile, "json-results", "",
+		"write test events as newline-delimited JSON to the specified path")
+	fs.StringVar(&c.capabilityReportFile, "capability-report", "",
+		"write a report of which tests were gated by each capability to the specified path")
 	fs.StringVar(&c.recordFailures, "record-failures", "", "record failed test IDs to the given file.\n"+
 		"recorded tests can be skipped by the next run of the harness via -skip-from")
 	fs.StringVar(&c.skipFile, "skip-from", "", "skips any test IDs recorded in the specified file.\n"+
-		"may be used in conjunction with -record-failures")
+		"may be used in conjunction with -record-failures. if the file name ends in .yaml, .yml, or .json,\n"+
+		"it is a suppression file with reasons and other options for each entry")
 	fs.IntVar(&c.queryTimeoutSeconds, "status-timeout", 10, "how many seconds to attempt to query to "+
 		"the test service before failing")
+	fs.IntVar(&c.commandTimeoutSeconds, "command-timeout", int(harness.DefaultCommandTimeout/time.Second),
+		"how many seconds to wait for the test service to respond to a command before failing the test "+
+			"(0 for no limit)")
+	fs.IntVar(&c.testTimeoutSeconds, "test-timeout", int(defaultTestTimeout/time.Second),
+		"how many seconds each test can run before it fails, unless the test sets its own limit (0 for no limit)")
+	fs.IntVar(&c.retryFailures, "retry-failures", 0, "how many times to run a failed test again; "+
+		"a test that passes on a retry is reported as flaky")
+	fs.BoolVar(&c.failOnFlaky, "fail-on-flaky", false,
+		"return a non-zero exit code if any tests were flaky, even if there were no failures")
+	fs.IntVar(&c.parallel, "parallel", 1, "maximum number of tests that can run at the same time, "+
+		"for tests that support it")
+	fs.BoolVar(&c.list, "list", false, "list the tests that would run, without running them; "+
+		"requires -capabilities or -status-file instead of -url")
+	fs.StringVar(&c.listCapabilities, "capabilities", "",
+		"comma-separated list of test service capabilities to use with -list")
+	fs.StringVar(&c.listStatusFile, "status-file", "",
+		"file containing a saved status response from a test service, to use with -list")
 
 	if err := fs.Parse(args[1:]); err != nil {
 		helpers.MustFprintln(os.Stderr, err)
 		fs.Usage()
 		return false
 	}
+	c.shard = ldtest.Shard{Index: 1, Count: 1}
+	if *shard != "" {
+		s, err := ldtest.ParseShard(*shard)
+		if err != nil {
+			helpers.MustFprintln(os.Stderr, err)
+			fs.Usage()
+			return false
+		}
+		c.shard = s
+	}
+	if c.retryFailures < 0 {
+		helpers.MustFprintln(os.Stderr, "-retry-failures cannot be negative")
+		fs.Usage()
+		return false
+	}
+	if c.parallel < 1 {
+		helpers.MustFprintln(os.Stderr, "-parallel must be at least 1")
+		fs.Usage()
+		return false
+	}
+	if c.list {
+		if (c.listCapabilities == "") == (c.listStatusFile == "") {
+			helpers.MustFprintln(os.Stderr, "-list requires either -capabilities or -status-file")
+			fs.Usage()
+			return false
+		}
+		return true
+	}
 	if c.serviceURL == "" {
 		helpers.MustFprintln(os.Stderr, "-url is required")
 		fs.Usage()
@@ -59,3 +131,11 @@ func (c *commandParams) Read(args []string) bool {
 	}
 	return true
 }
+
+// testFilter returns the filter for selecting tests, based on the filter parameters and the shard.
+func (c *commandParams) testFilter() ldtest.Filter {
+	if c.shard.Count > 1 {
+		return ldtest.ShardFilter{Filter: c.filters, Shard: c.shard}
+	}
+	return c.filters
+}
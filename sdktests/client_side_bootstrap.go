@@ -0,0 +1,151 @@
+package sdktests
+
+import (
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/data"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldreason"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+)
+
+func doClientSideBootstrapTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityBootstrap)
+
+	contexts := data.NewContextFactory("doClientSideBootstrapTests")
+	defaultValue := ldvalue.String("default")
+	expectedReason := ldreason.NewEvalReasonFallthrough()
+
+	// The data source never provides any data, so the only way the SDK can get these values is from
+	// the bootstrap data. The "version" property is deliberately different from "flagVersion", so that
+	// we can tell that the SDK is using the flag version from the bootstrap metadata in events.
+	untrackedFlag := mockld.ClientSDKFlag{Value: ldvalue.String("untracked-value"), Variation: o.Some(1),
+		Version: 100, FlagVersion: o.Some(1), Reason: o.Some(expectedReason)}
+	trackedFlag := mockld.ClientSDKFlag{Value: ldvalue.String("tracked-value"), Variation: o.Some(2),
+		Version: 200, FlagVersion: o.Some(2), Reason: o.Some(expectedReason), TrackEvents: true}
+	debuggedFlag := mockld.ClientSDKFlag{Value: ldvalue.String("debugged-value"), Variation: o.Some(3),
+		Version: 300, FlagVersion: o.Some(3), Reason: o.Some(expectedReason),
+		DebugEventsUntilDate: o.Some(ldtime.UnixMillisFromTime(time.Now().Add(time.Hour)))}
+	bootstrapData := mockld.NewClientSDKDataBuilder().
+		Flag("untracked-flag", untrackedFlag).
+		Flag("tracked-flag", trackedFlag).
+		Flag("debugged-flag", debuggedFlag).
+		Build()
+
+	setup := func(t *ldtest.T, context ldcontext.Context, configurers ...SDKConfigurer) *SDKClient {
+		dataSource := NewSDKDataSource(t, mockld.BlockingUnavailableSDKData(requireContext(t).sdkKind))
+		return NewSDKClient(t, append([]SDKConfigurer{
+			WithConfig(servicedef.SDKConfigParams{
+				StartWaitTimeMS: o.Some(ldtime.UnixMillisecondTime(1)),
+				InitCanFail:     true,
+			}),
+			WithClientSideInitialContext(context),
+			WithClientSideBootstrap(bootstrapData),
+			dataSource,
+		}, configurers...)...)
+	}
+
+	// The SDK may well have connected to the data source by the time we evaluate; we don't check for
+	// that, since SDKs are allowed to start connecting as soon as they are created. What matters is that
+	// the values are available even though the data source never responds.
+	t.Run("evaluations use bootstrap data while data source is unavailable", func(t *ldtest.T) {
+		client := setup(t, contexts.NextUniqueContext())
+
+		for _, flagKey := range []string{"untracked-flag", "tracked-flag", "debugged-flag"} {
+			flag := bootstrapData[flagKey]
+			t.Run(flagKey, func(t *ldtest.T) {
+				result := client.EvaluateFlag(t, servicedef.EvaluateFlagParams{
+					FlagKey:      flagKey,
+					ValueType:    servicedef.ValueTypeAny,
+					DefaultValue: defaultValue,
+					Detail:       true,
+				})
+				m.In(t).Assert(result, m.AllOf(
+					EvalResponseValue().Should(m.JSONEqual(flag.Value)),
+					EvalResponseVariation().Should(m.Equal(flag.Variation)),
+					EvalResponseReason().Should(EqualReason(expectedReason)),
+				))
+			})
+		}
+	})
+
+	t.Run("events use bootstrap metadata", func(t *ldtest.T) {
+		events := NewSDKEventSink(t)
+		eventContext := contexts.NextUniqueContext()
+		client := setup(t, eventContext, events)
+
+		client.FlushEvents(t)
+		_ = events.ExpectAnalyticsEvents(t, defaultEventTimeout) // discard initial identify event
+
+		evaluate := func(t *ldtest.T, flagKey string, flag mockld.ClientSDKFlag) {
+			result := client.EvaluateFlag(t, servicedef.EvaluateFlagParams{
+				FlagKey:      flagKey,
+				ValueType:    servicedef.ValueTypeAny,
+				DefaultValue: defaultValue,
+			})
+			m.In(t).Require(result.Value, m.JSONEqual(flag.Value))
+			client.FlushEvents(t)
+		}
+
+		t.Run("only summary event for untracked flag", func(t *ldtest.T) {
+			evaluate(t, "untracked-flag", untrackedFlag)
+			payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+			m.In(t).Assert(payload, m.ItemsInAnyOrder(
+				IsValidSummaryEventWithFlags(
+					t.Capabilities().Has(servicedef.CapabilityClientPerContextSummaries),
+					m.KV("untracked-flag", m.MapOf(
+						m.KV("default", m.JSONEqual(defaultValue)),
+						m.KV("counters", m.ItemsInAnyOrder(
+							flagCounter(untrackedFlag.Value, untrackedFlag.Variation.Value(),
+								untrackedFlag.FlagVersion.Value(), 1),
+						)),
+						m.KV("contextKinds", anyContextKindsList()),
+					)),
+				),
+			))
+		})
+
+		t.Run("feature event for flag with trackEvents", func(t *ldtest.T) {
+			evaluate(t, "tracked-flag", trackedFlag)
+			payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+			m.In(t).Assert(payload, m.ItemsInAnyOrder(
+				IsValidFeatureEventWithConditions(
+					t, false, eventContext,
+					m.JSONProperty("key").Should(m.Equal("tracked-flag")),
+					m.JSONProperty("version").Should(m.Equal(trackedFlag.FlagVersion.Value())),
+					m.JSONProperty("value").Should(m.JSONEqual(trackedFlag.Value)),
+					m.JSONProperty("variation").Should(m.JSONEqual(trackedFlag.Variation)),
+					maybeReason(false, expectedReason),
+					m.JSONProperty("default").Should(m.JSONEqual(defaultValue)),
+					JSONPropertyNullOrAbsent("prereqOf"),
+				),
+				IsSummaryEvent(),
+			))
+		})
+
+		t.Run("debug event for flag with debugEventsUntilDate", func(t *ldtest.T) {
+			evaluate(t, "debugged-flag", debuggedFlag)
+			payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+			m.In(t).Assert(payload, m.ItemsInAnyOrder(
+				m.AllOf(
+					IsDebugEvent(),
+					HasAnyCreationDate(),
+					m.JSONProperty("key").Should(m.Equal("debugged-flag")),
+					HasContextObjectWithMatchingKeys(eventContext),
+					m.JSONProperty("version").Should(m.Equal(debuggedFlag.FlagVersion.Value())),
+					m.JSONProperty("value").Should(m.JSONEqual(debuggedFlag.Value)),
+					m.JSONProperty("variation").Should(m.JSONEqual(debuggedFlag.Variation)),
+					m.JSONProperty("default").Should(m.JSONEqual(defaultValue)),
+				),
+				IsSummaryEvent(),
+			))
+		})
+	})
+}
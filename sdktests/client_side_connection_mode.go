@@ -0,0 +1,146 @@
+package sdktests
+
+import (
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
+	h "github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+)
+
+func doClientSideConnectionModeTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityConnectionMode)
+
+	c := NewCommonStreamingTests(t, "doClientSideConnectionModeTests")
+
+	flagKey := "flag"
+	valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+	defaultValue := ldvalue.String("defaultValue")
+	dataBefore := c.makeSDKDataWithFlag(flagKey, 1, valueBefore)
+	dataAfter := c.makeSDKDataWithFlag(flagKey, 2, valueAfter)
+	context := c.contextFactory.NextUniqueContext()
+
+	// The streaming and polling services are on the same endpoint, so we can see every request in order.
+	// JS-based client-side SDKs poll for their initial data before they connect to the stream, so any
+	// polling requests before the stream connection are skipped.
+	setup := func(t *ldtest.T, configurers ...SDKConfigurer) (*SDKDataSource, *SDKClient, harness.IncomingRequestInfo) {
+		dataSource := NewSDKDataSource(t, dataBefore, DataSourceOptionStreamingWithPolling())
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(
+			append(configurers, dataSource, WithClientSideInitialContext(context))...)...)
+		streamRequest := requireStreamConnection(t, dataSource.Endpoint(), time.Second*5)
+		m.In(t).Assert(basicEvaluateFlag(t, client, flagKey, context, defaultValue), m.JSONEqual(valueBefore))
+		return dataSource, client, streamRequest
+	}
+
+	t.Run("going offline closes stream connection", func(t *ldtest.T) {
+		_, client, streamRequest := setup(t)
+
+		client.SetOffline(t, true)
+		requireRequestClosed(t, streamRequest, "SDK did not close the stream connection when it went offline")
+	})
+
+	t.Run("no events are sent while offline", func(t *ldtest.T) {
+		events := NewSDKEventSink(t)
+		_, client, _ := setup(t, events)
+		client.FlushEvents(t)
+		_ = events.ExpectAnalyticsEvents(t, defaultEventTimeout) // initial identify event
+
+		client.SetOffline(t, true)
+		client.SendCustomEvent(t, servicedef.CustomEventParams{EventKey: "event-key"})
+		client.FlushEvents(t)
+
+		events.ExpectNoAnalyticsEvents(t, time.Millisecond*500)
+	})
+
+	t.Run("returning online reconnects and gets latest flags", func(t *ldtest.T) {
+		dataSource, client, streamRequest := setup(t)
+
+		client.SetOffline(t, true)
+		requireRequestClosed(t, streamRequest, "SDK did not close the stream connection when it went offline")
+
+		dataSource.SetInitialData(dataAfter)
+		client.SetOffline(t, false)
+
+		_ = requireStreamConnection(t, dataSource.Endpoint(), time.Second*5)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("switching to polling closes stream connection and polls", func(t *ldtest.T) {
+		dataSource, client, streamRequest := setup(t)
+
+		dataSource.SetInitialData(dataAfter)
+		client.SetConnectionMode(t, servicedef.ConnectionModePolling)
+		requireRequestClosed(t, streamRequest, "SDK did not close the stream connection when it switched to polling")
+
+		pollRequest := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+		m.In(t).For("request path").Assert(isClientSidePollingPath(pollRequest.URL.Path), m.Equal(true))
+		pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("switching back to streaming reconnects", func(t *ldtest.T) {
+		dataSource, client, _ := setup(t)
+
+		client.SetConnectionMode(t, servicedef.ConnectionModePolling)
+		dataSource.SetInitialData(dataAfter)
+		client.SetConnectionMode(t, servicedef.ConnectionModeStreaming)
+
+		_ = requireStreamConnection(t, dataSource.Endpoint(), time.Second*5)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("background closes stream connection and foreground reconnects", func(t *ldtest.T) {
+		if c.sdkKind != mockld.MobileSDK {
+			t.SkipWithReason("only mobile SDKs have a background mode")
+		}
+		dataSource, client, streamRequest := setup(t)
+
+		client.SetConnectionMode(t, servicedef.ConnectionModeBackground)
+		requireRequestClosed(t, streamRequest, "SDK did not close the stream connection in the background")
+
+		dataSource.SetInitialData(dataAfter)
+		client.SetConnectionMode(t, servicedef.ConnectionModeForeground)
+
+		_ = requireStreamConnection(t, dataSource.Endpoint(), time.Second*5)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+	})
+}
+
+// requireStreamConnection waits for a stream request on an endpoint that is shared by the streaming
+// and polling services, skipping any polling requests.
+func requireStreamConnection(
+	t *ldtest.T,
+	endpoint *harness.MockEndpoint,
+	timeout time.Duration,
+) harness.IncomingRequestInfo {
+	deadline := time.Now().Add(timeout)
+	request := endpoint.RequireConnection(t, time.Until(deadline))
+	for isClientSidePollingPath(request.URL.Path) {
+		request = endpoint.RequireConnection(t, time.Until(deadline))
+	}
+	return request
+}
+
+// requireRequestClosed waits until the SDK closes the connection for a request. Go's HTTP server cancels
+// the incoming request's Context when the client closes the underlying TCP connection.
+func requireRequestClosed(t *ldtest.T, request harness.IncomingRequestInfo, failureMessage string) {
+	h.RequireEventually(
+		t,
+		func() bool {
+			select {
+			case <-request.Context.Done():
+				return true
+			default:
+				return false
+			}
+		},
+		time.Second*3,
+		time.Millisecond*20,
+		failureMessage,
+	)
+}
@@ -33,7 +33,10 @@ func runParameterizedClientSideEvalTests(t *ldtest.T) {
 	for _, withReasons := range []bool{false, true} {
 		t.Run(fmt.Sprintf("evaluationReasons=%t", withReasons), func(t *ldtest.T) {
 			parameterizedTests := CommonEvalParameterizedTestRunner[mockld.ClientSDKData]{
-				SDKConfigurers: func(testSuite testmodel.EvalTestSuite[mockld.ClientSDKData]) []SDKConfigurer {
+				SDKConfigurers: func(
+					t *ldtest.T,
+					testSuite testmodel.EvalTestSuite[mockld.ClientSDKData],
+				) []SDKConfigurer {
 					if !testSuite.Context.IsDefined() {
 						t.Errorf("client-side test suite %q did not define a context", testSuite.Name)
 						t.FailNow()
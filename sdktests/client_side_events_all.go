@@ -20,7 +20,10 @@ func doClientSideEventTests(t *ldtest.T) {
 	t.Run("custom events", doClientSideCustomEventTests)
 	t.Run("context properties", doClientSideEventContextTests)
 	t.Run("event capacity", doClientSideEventBufferTests)
+	t.Run("diagnostic-events", doClientSideDiagnosticEventTests)
 	t.Run("disabling", doClientSideEventDisableTests)
+	t.Run("delivery", doClientSideEventDeliveryTests)
+	t.Run("close behavior", doClientSideEventCloseTests)
 
 	t.RequireCapability(servicedef.CapabilityClientPrereqEvents)
 	t.Run("prerequisite events emit in order", doClientSideInOrderPrereqEventTests)
@@ -87,6 +90,16 @@ func doClientSideEventDisableTests(t *ldtest.T) {
 		DisablingEvents(t)
 }
 
+func doClientSideEventDeliveryTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doClientSideEventDeliveryTests").
+		Delivery(t)
+}
+
+func doClientSideEventCloseTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doClientSideEventCloseTests").
+		CloseBehavior(t)
+}
+
 func doClientSideGzipEventRequestTests(t *ldtest.T) {
 	if !t.Capabilities().Has(servicedef.CapabilityEventGzip) {
 		return
@@ -121,3 +134,8 @@ func doClientSideGzipEventRequestTests(t *ldtest.T) {
 		})
 	}
 }
+
+func doClientSideDiagnosticEventTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doClientSideDiagnosticEventTests").
+		DiagnosticEvents(t)
+}
@@ -0,0 +1,119 @@
+package sdktests
+
+import (
+	"github.com/launchdarkly/sdk-test-harness/v2/data"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func doClientSideFlagCacheTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityClientFlagCache)
+
+	contexts := data.NewContextFactory("doClientSideFlagCacheTests")
+	flagKey := "flag"
+	flagValue, defaultValue := ldvalue.String("cached-value"), ldvalue.String("default")
+	sdkData := mockld.NewClientSDKDataBuilder().FlagWithValue(flagKey, 1, flagValue, 0).Build()
+
+	// Context keys from the factory are unique to this test run, so basing the namespace on one of them
+	// means that we won't see anything that a previous run left in the test service's storage.
+	nextNamespace := func() string {
+		return "flag-cache-" + contexts.NextUniqueContext().Key()
+	}
+
+	// startClientWithData starts a client that receives the flag data, and waits until it has the flag
+	// value for the context so that the value will have been cached.
+	startClientWithData := func(t *ldtest.T, context ldcontext.Context, configurers ...SDKConfigurer) *SDKClient {
+		dataSource := NewSDKDataSource(t, sdkData)
+		client := NewSDKClient(t, append(configurers, dataSource, WithClientSideInitialContext(context))...)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, defaultValue, flagValue, defaultValue)
+		return client
+	}
+
+	// startClientWithoutData starts a client whose data source never provides any data, so the only
+	// values it can have are the ones in its cache.
+	startClientWithoutData := func(t *ldtest.T, context ldcontext.Context, configurers ...SDKConfigurer) *SDKClient {
+		dataSource := NewSDKDataSource(t, mockld.BlockingUnavailableSDKData(requireContext(t).sdkKind))
+		return NewSDKClient(t, append(
+			[]SDKConfigurer{WithConfig(servicedef.SDKConfigParams{
+				StartWaitTimeMS: o.Some(ldtime.UnixMillisecondTime(1)),
+				InitCanFail:     true,
+			})},
+			append(configurers, dataSource, WithClientSideInitialContext(context))...,
+		)...)
+	}
+
+	t.Run("cached values are used after restart when data source is unavailable", func(t *ldtest.T) {
+		namespace := nextNamespace()
+		context := contexts.NextUniqueContext()
+
+		client1 := startClientWithData(t, context, WithClientSideFlagCache(namespace, o.None[int]()))
+		require.NoError(t, client1.Close())
+
+		client2 := startClientWithoutData(t, context, WithClientSideFlagCache(namespace, o.None[int]()))
+		pollUntilFlagValueUpdated(t, client2, flagKey, context, defaultValue, flagValue, defaultValue)
+	})
+
+	t.Run("cached values are not used for a different context", func(t *ldtest.T) {
+		namespace := nextNamespace()
+		context1, context2 := contexts.NextUniqueContext(), contexts.NextUniqueContext()
+
+		client1 := startClientWithData(t, context1, WithClientSideFlagCache(namespace, o.None[int]()))
+		require.NoError(t, client1.Close())
+
+		client2 := startClientWithoutData(t, context2, WithClientSideFlagCache(namespace, o.None[int]()))
+		m.In(t).Assert(basicEvaluateFlag(t, client2, flagKey, context2, defaultValue), m.JSONEqual(defaultValue))
+		require.NoError(t, client2.Close())
+
+		// Make sure the values were really cached, so the check above means something.
+		client3 := startClientWithoutData(t, context1, WithClientSideFlagCache(namespace, o.None[int]()))
+		pollUntilFlagValueUpdated(t, client3, flagKey, context1, defaultValue, flagValue, defaultValue)
+	})
+
+	t.Run("least recently used context is evicted past max cached contexts", func(t *ldtest.T) {
+		namespace := nextNamespace()
+		context1, context2 := contexts.NextUniqueContext(), contexts.NextUniqueContext()
+		flagCache := WithClientSideFlagCache(namespace, o.Some(1))
+
+		client1 := startClientWithData(t, context1, flagCache)
+		client1.SendIdentifyEvent(t, context2)
+		pollUntilFlagValueUpdated(t, client1, flagKey, context2, defaultValue, flagValue, defaultValue)
+		require.NoError(t, client1.Close())
+
+		// Check context2 first: starting a client with context1 could legitimately cause the SDK to record
+		// context1 in the cache, evicting context2.
+		client2 := startClientWithoutData(t, context2, flagCache)
+		pollUntilFlagValueUpdated(t, client2, flagKey, context2, defaultValue, flagValue, defaultValue)
+		require.NoError(t, client2.Close())
+
+		client3 := startClientWithoutData(t, context1, flagCache)
+		m.In(t).Assert(basicEvaluateFlag(t, client3, flagKey, context1, defaultValue), m.JSONEqual(defaultValue))
+	})
+
+	t.Run("cache is isolated between credentials", func(t *ldtest.T) {
+		namespace := nextNamespace()
+		context := contexts.NextUniqueContext()
+
+		client1 := startClientWithData(t, context, WithCredential("credential-1"),
+			WithClientSideFlagCache(namespace, o.None[int]()))
+		require.NoError(t, client1.Close())
+
+		client2 := startClientWithoutData(t, context, WithCredential("credential-2"),
+			WithClientSideFlagCache(namespace, o.None[int]()))
+		m.In(t).Assert(basicEvaluateFlag(t, client2, flagKey, context, defaultValue), m.JSONEqual(defaultValue))
+		require.NoError(t, client2.Close())
+
+		// Make sure the values were really cached, so the check above means something.
+		client3 := startClientWithoutData(t, context, WithCredential("credential-1"),
+			WithClientSideFlagCache(namespace, o.None[int]()))
+		pollUntilFlagValueUpdated(t, client3, flagKey, context, defaultValue, flagValue, defaultValue)
+	})
+}
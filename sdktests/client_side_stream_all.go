@@ -20,6 +20,8 @@ func doClientSideStreamTests(t *ldtest.T) {
 	t.Run("updates", doClientSideStreamUpdateTests)
 	t.Run("retry behavior", doClientSideStreamRetryTests)
 	t.Run("connection lifecycle", doClientSideStreamConnectionLifecycleTests)
+	t.Run("ping", doClientSideStreamPingTests)
+	t.Run("stall detection", NewCommonStreamingTests(t, "doClientSideStreamStallTests").StallDetection)
 }
 
 func doClientSideStreamRequestTest(t *ldtest.T) {
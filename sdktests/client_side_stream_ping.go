@@ -0,0 +1,74 @@
+package sdktests
+
+import (
+	"strings"
+	"time"
+
+	h "github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+)
+
+func doClientSideStreamPingTests(t *ldtest.T) {
+	// A "ping" event on the stream tells a client-side SDK that the flag data has changed, without
+	// including the data; the SDK should then request the latest data from the polling service, using
+	// the same context and request method that it uses for the stream. In these tests, the streaming
+	// and polling services are on the same endpoint, so we can see the order of the requests.
+	sdkKind := requireContext(t).sdkKind
+	envIDOrMobileKey := "my-credential"
+	c := NewCommonStreamingTests(t, "doClientSideStreamPingTests", WithCredential(envIDOrMobileKey))
+
+	flagKey := "flag"
+	valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+	defaultValue := ldvalue.String("defaultValue")
+	dataBefore := c.makeSDKDataWithFlag(flagKey, 1, valueBefore)
+	dataAfter := c.makeSDKDataWithFlag(flagKey, 2, valueAfter)
+
+	pollGetPath := h.IfElse(sdkKind == mockld.MobileSDK || sdkKind == mockld.RokuSDK,
+		mockld.PollingPathMobileGet,
+		strings.ReplaceAll(mockld.PollingPathJSClientGet, mockld.PollingPathEnvIDParam, envIDOrMobileKey))
+	pollReportPath := h.IfElse(sdkKind == mockld.MobileSDK || sdkKind == mockld.RokuSDK,
+		mockld.PollingPathMobileReport,
+		strings.ReplaceAll(mockld.PollingPathJSClientReport, mockld.PollingPathEnvIDParam, envIDOrMobileKey))
+
+	for _, method := range c.availableFlagRequestMethods() {
+		t.Run(string(method), func(t *ldtest.T) {
+			context := c.contextFactory.NextUniqueContext()
+			dataSource := NewSDKDataSource(t, dataBefore, DataSourceOptionStreamingWithPolling())
+			client := NewSDKClient(t, c.baseSDKConfigurationPlus(
+				dataSource,
+				WithClientSideInitialContext(context),
+				c.withFlagRequestMethod(method),
+			)...)
+
+			// JS-based client-side SDKs poll for their initial data before they connect to the stream,
+			// so skip any requests until we see the stream connection.
+			_ = requireStreamConnection(t, dataSource.Endpoint(), time.Second*5)
+
+			m.In(t).Assert(basicEvaluateFlag(t, client, flagKey, context, defaultValue), m.JSONEqual(valueBefore))
+
+			dataSource.SetInitialData(dataAfter)
+			dataSource.StreamingService().PushPing()
+
+			pollRequest := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+			m.In(t).For("request method").Assert(pollRequest.Method, m.Equal(string(method)))
+			if method == flagRequestREPORT {
+				m.In(t).For("request path").Assert(pollRequest.URL.Path, m.Equal(pollReportPath))
+				m.In(t).For("request body").Assert(pollRequest.Body, m.AllOf(
+					m.Not(m.BeNil()),
+					JSONMatchesContext(context)))
+			} else {
+				getPathPrefix := strings.TrimSuffix(pollGetPath, mockld.PollingPathContextBase64Param)
+				m.In(t).For("request path").Require(pollRequest.URL.Path, m.StringHasPrefix(getPathPrefix))
+				m.In(t).For("context data in URL").Assert(strings.TrimPrefix(pollRequest.URL.Path, getPathPrefix),
+					Base64DecodedData().Should(JSONMatchesContext(context)))
+			}
+
+			pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+		})
+	}
+}
@@ -137,17 +137,14 @@ type transportProtocol struct {
 
 // Run invokes T.Run() with the protocol's name, passing in a modified T that is suitable for the test.
 func (t transportProtocol) Run(tester *ldtest.T, action func(*ldtest.T)) {
-	// This is a pretty nasty hack. We're modifying the TestHarness that is stashed away in T, in order
-	// to tell it to use HTTPS when creating mock endpoints. This is necessary because higher level
-	// test components - like the mock data sources or event sink - use those methods in their own setup.
-	// So, if this is a test that should use HTTPS, tweak the global TestHarness and enable it - then undo
-	// it after the test runs. WARNING: this won't work with tests that run in parallel.
-
-	// Ensure that if some test fails/panics, we are back to using HTTP by default for the next one.
-	defer requireContext(tester).harness.SetService("http")
-
+	// Higher level test components - like the mock data sources or event sink - create their mock
+	// endpoints using the TestHarness from the test context. So, we give this test its own context
+	// whose TestHarness creates endpoints with the right protocol. Since this only affects the test
+	// scope and its subtests, it is safe for tests that run in parallel.
 	tester.Run(t.tag, func(tester *ldtest.T) {
-		requireContext(tester).harness.SetService(t.protocol)
+		ctx := requireContext(tester)
+		ctx.harness = ctx.harness.WithService(t.protocol)
+		tester.SetContext(ctx)
 		action(tester)
 	})
 }
@@ -17,7 +17,7 @@ import (
 )
 
 type CommonEvalParameterizedTestRunner[SDKDataType mockld.SDKData] struct {
-	SDKConfigurers       func(testmodel.EvalTestSuite[SDKDataType]) []SDKConfigurer
+	SDKConfigurers       func(*ldtest.T, testmodel.EvalTestSuite[SDKDataType]) []SDKConfigurer
 	FilterSDKData        func(SDKDataType) SDKDataType
 	FilterExpectedReason func(ldreason.EvaluationReason) ldreason.EvaluationReason
 }
@@ -43,13 +43,17 @@ func (c CommonEvalParameterizedTestRunner[SDKDataType]) RunAll(t *ldtest.T, dirN
 	testSuites := data.LoadAndParseAllTestSuites[testmodel.EvalTestSuite[SDKDataType]](t, dirName)
 	groups := data.GroupTestSuitesByName(testSuites)
 
+	// Each group uses its own data source and SDK client, so the groups can run in parallel.
+
 	for _, group := range groups {
 		if len(group) == 1 {
 			t.Run(group[0].Name, func(t *ldtest.T) {
+				t.Parallel()
 				c.runTestSuite(t, group[0])
 			})
 		} else {
 			t.Run(group[0].Name, func(t *ldtest.T) {
+				t.Parallel()
 				for _, suite := range group {
 					c.runTestSuite(t, suite)
 				}
@@ -74,7 +78,7 @@ func (c CommonEvalParameterizedTestRunner[SDKDataType]) runTestSuite(
 
 	var clientConfig []SDKConfigurer
 	if c.SDKConfigurers != nil {
-		clientConfig = c.SDKConfigurers(suite)
+		clientConfig = c.SDKConfigurers(t, suite)
 	}
 	client := NewSDKClient(t, append(clientConfig, dataSource)...)
 
@@ -0,0 +1,84 @@
+package sdktests
+
+import (
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	"github.com/launchdarkly/go-server-sdk-evaluation/v3/ldbuilders"
+
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CloseBehavior verifies that when the SDK client is closed, it delivers any analytics events that
+// have not yet been flushed, including the summary of evaluations; and that after it is closed, it
+// does not send any more events or reconnect to the stream.
+func (c CommonEventTests) CloseBehavior(t *ldtest.T) {
+	flagKey := "flag"
+	flagValue, defaultValue := ldvalue.String("value"), ldvalue.String("default")
+	flagVersion, flagVariation := 10, 1
+	context := c.contextFactory.NextUniqueContext()
+
+	var data mockld.SDKData
+	if c.isClientSide {
+		data = mockld.NewClientSDKDataBuilder().Flag(flagKey, mockld.ClientSDKFlag{
+			Value:     flagValue,
+			Variation: o.Some(flagVariation),
+			Version:   flagVersion,
+		}).Build()
+	} else {
+		data = mockld.NewServerSDKDataBuilder().Flag(ldbuilders.NewFlagBuilder(flagKey).Version(flagVersion).
+			On(false).OffVariation(flagVariation).Variations(ldvalue.String("other"), flagValue).
+			Build()).Build()
+	}
+
+	// How long to wait for any unwanted activity after the client has been closed
+	noMoreActivityTimeout := time.Millisecond * 500
+
+	t.Run("pending events are flushed and nothing is sent afterward", func(t *ldtest.T) {
+		stream, configurers := CommonStreamingTests{c.commonTestsBase}.setupDataSources(t, data)
+		events := NewSDKEventSink(t)
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(
+			append(configurers, events, WithClientSideInitialContext(context))...)...)
+		streamRequest := stream.Endpoint().RequireConnection(t, time.Second*5)
+
+		for i := 0; i < 2; i++ {
+			_ = basicEvaluateFlag(t, client, flagKey, context, defaultValue)
+		}
+		params := servicedef.CustomEventParams{EventKey: "event-key"}
+		if !c.isClientSide {
+			params.Context = o.Some(context)
+		}
+		client.SendCustomEvent(t, params)
+
+		// The flush interval is very long (see baseEventsConfig), so nothing should be delivered yet.
+		events.ExpectNoAnalyticsEvents(t, time.Millisecond*100)
+
+		require.NoError(t, client.Close())
+		requireRequestClosed(t, streamRequest, "timed out waiting for SDK to close the stream connection")
+
+		payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+		m.In(t).Assert(payload, m.ItemsInAnyOrder(append(
+			c.initialEventPayloadExpectations(),
+			c.eventsWithIndexEventIfAppropriate(
+				IsCustomEventForEventKey("event-key"),
+				IsValidSummaryEventWithFlags(
+					c.isClientSide && t.Capabilities().Has(servicedef.CapabilityClientPerContextSummaries),
+					m.KV(flagKey, m.MapOf(
+						m.KV("default", m.JSONEqual(defaultValue)),
+						m.KV("counters", m.Items(flagCounter(flagValue, flagVariation, flagVersion, 2))),
+						m.KV("contextKinds", anyContextKindsList()),
+					)),
+				),
+			)...)...))
+
+		events.ExpectNoAnalyticsEvents(t, noMoreActivityTimeout)
+		stream.Endpoint().RequireNoMoreConnections(t, noMoreActivityTimeout)
+	})
+}
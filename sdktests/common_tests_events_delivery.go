@@ -0,0 +1,112 @@
+package sdktests
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+)
+
+const payloadIDHeader = "X-LaunchDarkly-Payload-ID"
+
+// Delivery verifies how the SDK handles an error response when it posts analytics events: after a
+// recoverable error, it should retry the post once, with the same payload ID; if the retry also fails,
+// it should discard the payload. After a 413 error, meaning the payload was too large, it should
+// discard the payload without retrying, but keep sending later payloads; and after a 401 or 403 error,
+// it should stop sending events.
+func (c CommonEventTests) Delivery(t *ldtest.T) {
+	// SDKs wait about a second before retrying a post.
+	retryTimeout := time.Second * 5
+
+	// When we're asserting that there is no retry, we need to wait long enough that an SDK that was
+	// going to retry would have done so.
+	noRetryTimeout := time.Second * 2
+
+	setup := func(t *ldtest.T, statuses ...int) (*SDKEventSink, *SDKClient) {
+		dataSource := NewSDKDataSource(t, nil)
+		events := NewSDKEventSink(t)
+		events.Service().SetIgnoreDuplicatePayload(false)
+		events.Service().SetAnalyticsPostStatuses(statuses...)
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(dataSource, events)...)
+		return events, client
+	}
+
+	sendEventAndFlush := func(t *ldtest.T, client *SDKClient, eventKey string) {
+		params := servicedef.CustomEventParams{EventKey: eventKey}
+		if !c.isClientSide {
+			params.Context = o.Some(ldcontext.New("user-key"))
+		}
+		client.SendCustomEvent(t, params)
+		client.FlushEvents(t)
+	}
+
+	t.Run("retries once after recoverable error with same payload ID", func(t *ldtest.T) {
+		events, client := setup(t, http.StatusServiceUnavailable, http.StatusAccepted)
+		sendEventAndFlush(t, client, "event1")
+
+		request1 := events.Endpoint().RequireConnection(t, defaultEventTimeout)
+		request2 := events.Endpoint().RequireConnection(t, retryTimeout)
+		payloadID := request1.Headers.Get(payloadIDHeader)
+		m.In(t).For("payload ID").Require(payloadID, m.Not(m.Equal("")))
+		m.In(t).For("payload ID of retry").Assert(request2.Headers.Get(payloadIDHeader), m.Equal(payloadID))
+
+		payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+		m.In(t).Assert(payload, m.ItemsInAnyOrder(append(c.initialEventPayloadExpectations(),
+			c.eventsWithIndexEventIfAppropriate(IsCustomEventForEventKey("event1"))...)...))
+	})
+
+	t.Run("discards payload after retry fails", func(t *ldtest.T) {
+		events, client := setup(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusAccepted)
+		sendEventAndFlush(t, client, "event1")
+
+		request1 := events.Endpoint().RequireConnection(t, defaultEventTimeout)
+		request2 := events.Endpoint().RequireConnection(t, retryTimeout)
+		m.In(t).For("payload ID of retry").Assert(request2.Headers.Get(payloadIDHeader),
+			m.Equal(request1.Headers.Get(payloadIDHeader)))
+		events.Endpoint().RequireNoMoreConnections(t, noRetryTimeout)
+
+		sendEventAndFlush(t, client, "event2")
+
+		request3 := events.Endpoint().RequireConnection(t, defaultEventTimeout)
+		m.In(t).For("payload ID of new payload").Assert(request3.Headers.Get(payloadIDHeader),
+			m.Not(m.Equal(request1.Headers.Get(payloadIDHeader))))
+		payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+		m.In(t).Assert(payload, m.ItemsInAnyOrder(IsCustomEventForEventKey("event2")))
+	})
+
+	t.Run("discards payload without retrying after error 413", func(t *ldtest.T) {
+		events, client := setup(t, http.StatusRequestEntityTooLarge, http.StatusAccepted)
+		sendEventAndFlush(t, client, "event1")
+
+		request1 := events.Endpoint().RequireConnection(t, defaultEventTimeout)
+		events.Endpoint().RequireNoMoreConnections(t, noRetryTimeout)
+
+		sendEventAndFlush(t, client, "event2")
+
+		request2 := events.Endpoint().RequireConnection(t, defaultEventTimeout)
+		m.In(t).For("payload ID of new payload").Assert(request2.Headers.Get(payloadIDHeader),
+			m.Not(m.Equal(request1.Headers.Get(payloadIDHeader))))
+		payload := events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+		m.In(t).Assert(payload, m.ItemsInAnyOrder(IsCustomEventForEventKey("event2")))
+	})
+
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		t.Run(fmt.Sprintf("stops sending events after error %d", status), func(t *ldtest.T) {
+			events, client := setup(t, status)
+			sendEventAndFlush(t, client, "event1")
+
+			_ = events.Endpoint().RequireConnection(t, defaultEventTimeout)
+			events.Endpoint().RequireNoMoreConnections(t, noRetryTimeout)
+
+			sendEventAndFlush(t, client, "event2")
+			events.Endpoint().RequireNoMoreConnections(t, noRetryTimeout)
+		})
+	}
+}
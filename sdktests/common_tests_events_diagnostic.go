@@ -0,0 +1,198 @@
+package sdktests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The minimum diagnostic recording interval that server-side SDKs allow. Some client-side SDKs have
+// a higher minimum; their test services should bypass it if possible.
+const diagnosticRecordingInterval = time.Minute
+
+func IsDiagnosticInitEvent() m.Matcher     { return EventHasKind("diagnostic-init") }
+func IsDiagnosticPeriodicEvent() m.Matcher { return EventHasKind("diagnostic") }
+
+func (c CommonEventTests) DiagnosticEvents(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityDiagnosticEvents)
+
+	credential := "my-credential-abcdef"
+	capacity := 2
+	flushIntervalMS := ldtime.UnixMillisecondTime(1000000)
+
+	diagnosticEventsConfig := func() servicedef.SDKConfigEventParams {
+		config := baseEventsConfig()
+		config.EnableDiagnostics = true
+		config.Capacity = o.Some(capacity)
+		config.FlushIntervalMS = o.Some(flushIntervalMS)
+		config.EnableGzip = o.Some(t.Capabilities().Has(servicedef.CapabilityEventGzip))
+		return config
+	}
+
+	dataSource := NewSDKDataSource(t, nil)
+
+	t.Run("init event", func(t *ldtest.T) {
+		events := NewSDKEventSinkWithGzip(t, t.Capabilities().Has(servicedef.CapabilityEventGzip))
+		_ = NewSDKClient(t, c.baseSDKConfigurationPlus(
+			WithCredential(credential),
+			WithEventsConfig(diagnosticEventsConfig()),
+			dataSource,
+			events)...)
+
+		initEvent := events.ExpectDiagnosticEvent(t, defaultEventTimeout)
+		m.In(t).Require(initEvent, IsDiagnosticInitEvent())
+
+		t.Run("id", func(t *ldtest.T) {
+			m.In(t).Assert(initEvent, m.AllOf(
+				HasAnyCreationDate(),
+				m.JSONProperty("id").Should(m.JSONProperty("diagnosticId").Should(m.Not(m.Equal("")))),
+			))
+			keySuffix := credential[len(credential)-6:]
+			switch {
+			case !c.isClientSide:
+				m.In(t).Assert(initEvent, m.JSONProperty("id").Should(
+					m.JSONProperty("sdkKeySuffix").Should(m.Equal(keySuffix))))
+			case c.isMobile:
+				m.In(t).Assert(initEvent, m.JSONProperty("id").Should(
+					m.JSONProperty("mobileKeySuffix").Should(m.Equal(keySuffix))))
+			}
+		})
+
+		t.Run("sdk and platform identity", func(t *ldtest.T) {
+			m.In(t).Assert(initEvent, m.AllOf(
+				m.JSONProperty("sdk").Should(m.AllOf(
+					m.JSONProperty("name").Should(m.Not(m.Equal(""))),
+					m.JSONProperty("version").Should(m.Not(m.Equal(""))),
+				)),
+				m.JSONProperty("platform").Should(
+					m.JSONProperty("name").Should(m.Not(m.Equal(""))),
+				),
+			))
+		})
+
+		t.Run("configuration", func(t *ldtest.T) {
+			matchers := []m.Matcher{
+				m.JSONProperty("customEventsURI").Should(m.Equal(true)),
+				m.JSONProperty("eventsCapacity").Should(m.Equal(capacity)),
+				m.JSONProperty("eventsFlushIntervalMillis").Should(m.Equal(int(flushIntervalMS))),
+			}
+			if !c.isClientSide {
+				matchers = append(matchers,
+					m.JSONProperty("customStreamURI").Should(m.Equal(true)),
+					m.JSONProperty("streamingDisabled").Should(m.Equal(false)),
+				)
+			}
+			m.In(t).Assert(initEvent, m.JSONProperty("configuration").Should(m.AllOf(matchers...)))
+		})
+	})
+
+	t.Run("wrapper identity", func(t *ldtest.T) {
+		t.RequireCapability(servicedef.CapabilityWrapper)
+
+		events := NewSDKEventSinkWithGzip(t, t.Capabilities().Has(servicedef.CapabilityEventGzip))
+		_ = NewSDKClient(t, c.baseSDKConfigurationPlus(
+			WithCredential(credential),
+			WithEventsConfig(diagnosticEventsConfig()),
+			helpers.ConfigOptionFunc[servicedef.SDKConfigParams](func(config *servicedef.SDKConfigParams) error {
+				config.Wrapper = o.Some(servicedef.SDKConfigWrapper{Name: "my-wrapper", Version: "1.2.3"})
+				return nil
+			}),
+			dataSource,
+			events)...)
+
+		initEvent := events.ExpectDiagnosticEvent(t, defaultEventTimeout)
+		m.In(t).Assert(initEvent, m.JSONProperty("sdk").Should(m.AllOf(
+			m.JSONProperty("wrapperName").Should(m.Equal("my-wrapper")),
+			m.JSONProperty("wrapperVersion").Should(m.Equal("1.2.3")),
+		)))
+	})
+
+	t.Run("no diagnostic events if disabled", func(t *ldtest.T) {
+		events := NewSDKEventSinkWithGzip(t, t.Capabilities().Has(servicedef.CapabilityEventGzip))
+		eventsConfig := diagnosticEventsConfig()
+		eventsConfig.EnableDiagnostics = false
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(
+			WithEventsConfig(eventsConfig),
+			dataSource,
+			events)...)
+
+		c.sendArbitraryEvent(t, client)
+		client.FlushEvents(t)
+		_ = events.ExpectAnalyticsEvents(t, defaultEventTimeout)
+		events.ExpectNoDiagnosticEvents(t, time.Millisecond*200)
+	})
+
+	t.Run("periodic event", func(t *ldtest.T) {
+		t.LongRunning()
+
+		events := NewSDKEventSinkWithGzip(t, t.Capabilities().Has(servicedef.CapabilityEventGzip))
+		eventsConfig := diagnosticEventsConfig()
+		eventsConfig.DiagnosticRecordingIntervalMS = o.Some(
+			ldtime.UnixMillisecondTime(diagnosticRecordingInterval.Milliseconds()))
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(
+			WithCredential(credential),
+			WithEventsConfig(eventsConfig),
+			dataSource,
+			events)...)
+
+		initEvent := events.ExpectDiagnosticEvent(t, defaultEventTimeout)
+		initReceivedTime := time.Now()
+		m.In(t).Require(initEvent, IsDiagnosticInitEvent())
+
+		// Fill the buffer past capacity with events for a single context. A client-side SDK has already
+		// queued an identify event for its initial context; for a server-side SDK, we send one ourselves.
+		// Either way, the custom events that follow don't generate index events, and two of them are dropped.
+		context := c.contextFactory.NextUniqueContext()
+		if !c.isClientSide {
+			client.SendIdentifyEvent(t, context)
+		}
+		for i := 0; i < capacity+1; i++ {
+			params := servicedef.CustomEventParams{EventKey: fmt.Sprintf("event%d", i)}
+			if !c.isClientSide {
+				params.Context = o.Some(context)
+			}
+			client.SendCustomEvent(t, params)
+		}
+		expectedDropped := 2
+
+		periodicEvent := events.ExpectDiagnosticEvent(t, diagnosticRecordingInterval+time.Second*10)
+		m.In(t).Require(periodicEvent, IsDiagnosticPeriodicEvent())
+
+		t.Run("interval", func(t *ldtest.T) {
+			// Allow some leeway for the time it took to deliver the init event to us.
+			assert.GreaterOrEqual(t, time.Since(initReceivedTime), diagnosticRecordingInterval-time.Second*2)
+			m.In(t).Assert(periodicEvent, m.AllOf(
+				HasAnyCreationDate(),
+				m.JSONProperty("dataSinceDate").Should(m.Equal(initEvent.AsValue().GetByKey("creationDate"))),
+				m.JSONProperty("id").Should(m.Equal(initEvent.AsValue().GetByKey("id"))),
+			))
+		})
+
+		t.Run("droppedEvents", func(t *ldtest.T) {
+			m.In(t).Assert(periodicEvent, m.JSONProperty("droppedEvents").Should(m.Equal(expectedDropped)))
+		})
+
+		t.Run("deduplicatedUsers", func(t *ldtest.T) {
+			deduplicated := periodicEvent.AsValue().GetByKey("deduplicatedUsers")
+			require.Equal(t, ldvalue.NumberType, deduplicated.Type(), "deduplicatedUsers should be a number")
+			if c.isClientSide {
+				// Client-side SDKs don't send index events, so there is nothing to deduplicate.
+				assert.Equal(t, 0, deduplicated.IntValue())
+			} else {
+				// The custom events all referenced a context that the SDK had already seen.
+				assert.Greater(t, deduplicated.IntValue(), 0)
+			}
+		})
+	})
+}
@@ -0,0 +1,75 @@
+package sdktests
+
+import (
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+)
+
+// StallDetection verifies that the SDK treats a stream that is still open, but has not sent anything
+// for longer than the configured read timeout, as dead and reconnects; and that the heartbeat comments
+// that LaunchDarkly sends on an idle stream prevent that from happening.
+func (c CommonStreamingTests) StallDetection(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityStreamingReadTimeout)
+	t.LongRunning()
+
+	readTimeout := time.Second * 2
+	heartbeatInterval := readTimeout / 4
+
+	// Not every client-side SDK allows the stream retry delay to be configured, so allow for a
+	// default initial delay of about a second, on top of the read timeout.
+	reconnectTimeout := readTimeout + time.Second*10
+
+	flagKey := "flag"
+	valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+	defaultValue := ldvalue.String("defaultValue")
+	dataBefore := c.makeSDKDataWithFlag(flagKey, 1, valueBefore)
+	dataAfter := c.makeSDKDataWithFlag(flagKey, 2, valueAfter)
+	context := ldcontext.New("context-key")
+
+	withReadTimeout := helpers.ConfigOptionFunc[servicedef.SDKConfigParams](func(
+		config *servicedef.SDKConfigParams,
+	) error {
+		streaming := config.Streaming.Value()
+		streaming.ReadTimeoutMS = o.Some(ldtime.UnixMillisecondTime(readTimeout.Milliseconds()))
+		streaming.InitialRetryDelayMS = o.Some(briefDelay)
+		config.Streaming = o.Some(streaming)
+		return nil
+	})
+
+	t.Run("reconnects after stream stalls for longer than read timeout", func(t *ldtest.T) {
+		stream, configurers := c.setupDataSources(t, dataBefore)
+		stream.StreamingService().StartHeartbeat(heartbeatInterval)
+		t.Defer(stream.StreamingService().StopHeartbeat)
+
+		client := NewSDKClient(t, c.baseSDKConfigurationPlus(append(configurers, withReadTimeout)...)...)
+		_ = stream.Endpoint().RequireConnection(t, time.Second*5)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, defaultValue, valueBefore, defaultValue)
+
+		// The new data is only available if the SDK makes a new connection, since the stalled
+		// connection won't receive anything.
+		stream.SetInitialData(dataAfter)
+		stream.StreamingService().StallActiveConnections()
+
+		_ = stream.Endpoint().RequireConnection(t, reconnectTimeout)
+		pollUntilFlagValueUpdated(t, client, flagKey, context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("heartbeats keep an idle stream open", func(t *ldtest.T) {
+		stream, configurers := c.setupDataSources(t, dataBefore)
+		stream.StreamingService().StartHeartbeat(heartbeatInterval)
+		t.Defer(stream.StreamingService().StopHeartbeat)
+
+		_ = NewSDKClient(t, c.baseSDKConfigurationPlus(append(configurers, withReadTimeout)...)...)
+		_ = stream.Endpoint().RequireConnection(t, time.Second*5)
+
+		stream.Endpoint().RequireNoMoreConnections(t, readTimeout*3)
+	})
+}
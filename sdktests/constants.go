@@ -6,4 +6,6 @@ const (
 	tagNameAppVersion  = "application-version"
 
 	environmentIDHeader = "X-LD-EnvID"
+
+	fdv2PayloadID = "payload"
 )
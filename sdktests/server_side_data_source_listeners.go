@@ -0,0 +1,257 @@
+package sdktests
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
+	h "github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	"github.com/launchdarkly/go-server-sdk-evaluation/v3/ldbuilders"
+	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+	"github.com/launchdarkly/go-test-helpers/v2/jsonhelpers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doServerSideDataSourceStatusTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityDataSourceStatus)
+
+	flag := ldbuilders.NewFlagBuilder("flag").Version(1).
+		On(false).OffVariation(0).Variations(ldvalue.String("a")).Build()
+	data := mockld.NewServerSDKDataBuilder().Flag(flag).Build()
+
+	incomingConnectionTimeout := time.Second * 2
+
+	// Each of these tests uses a sequence of handlers for successive stream connections. We set a
+	// very short retry delay so that the SDK moves through the sequence quickly.
+	makeClientWithStreamHandlers := func(
+		t *ldtest.T,
+		initCanFail bool,
+		handlers ...http.Handler,
+	) (*SDKClient, *harness.MockEndpoint) {
+		endpoint := requireContext(t).harness.NewMockEndpoint(
+			httphelpers.SequentialHandler(handlers[0], handlers[1:]...),
+			t.DebugLogger(), harness.MockEndpointDescription("streaming service"))
+		t.Defer(endpoint.Close)
+		config := servicedef.SDKConfigParams{Streaming: o.Some(baseStreamConfig(endpoint))}
+		if initCanFail {
+			config.InitCanFail = true
+			config.StartWaitTimeMS = o.Some(ldtime.UnixMillisecondTime(1))
+		}
+		return NewSDKClient(t, WithConfig(config)), endpoint
+	}
+
+	t.Run("status is VALID after successful initialization", func(t *ldtest.T) {
+		stream := NewSDKDataSourceWithoutEndpoint(t, data, DataSourceOptionStreaming())
+		client, _ := makeClientWithStreamHandlers(t, false, stream.Handler())
+
+		status := client.GetDataSourceStatus(t)
+		assert.Equal(t, servicedef.DataSourceStateValid, status.State)
+		assert.NotEqual(t, ldtime.UnixMillisecondTime(0), status.StateSince)
+	})
+
+	t.Run("status is INITIALIZING while initial connection is failing", func(t *ldtest.T) {
+		client, endpoint := makeClientWithStreamHandlers(t, true, httphelpers.HandlerWithStatus(503))
+		_ = endpoint.RequireConnection(t, incomingConnectionTimeout)
+
+		status := client.GetDataSourceStatus(t)
+		assert.Equal(t, servicedef.DataSourceStateInitializing, status.State)
+	})
+
+	t.Run("status includes last error", func(t *ldtest.T) {
+		client, endpoint := makeClientWithStreamHandlers(t, true, httphelpers.HandlerWithStatus(503))
+		_ = endpoint.RequireConnection(t, incomingConnectionTimeout)
+
+		// The status is updated asynchronously after the failed request, so we may need to check more than once.
+		var status servicedef.DataSourceStatus
+		h.RequireEventually(t, func() bool {
+			status = client.GetDataSourceStatus(t)
+			return status.LastError.IsDefined()
+		}, time.Second, time.Millisecond*50, "timed out waiting for data source status to include last error")
+		assert.Equal(t, servicedef.DataSourceErrorKindErrorResponse, status.LastError.Value().Kind)
+		assert.Equal(t, o.Some(503), status.LastError.Value().StatusCode)
+	})
+
+	t.Run("listener is notified of INTERRUPTED and VALID after stream failure", func(t *ldtest.T) {
+		stream := NewSDKDataSourceWithoutEndpoint(t, data, DataSourceOptionStreaming())
+		client, endpoint := makeClientWithStreamHandlers(t, false,
+			stream.Handler(),                   // first request succeeds
+			httphelpers.HandlerWithStatus(503), // second request gets a recoverable error
+			stream.Handler(),                   // third request succeeds again
+		)
+		require.Equal(t, servicedef.DataSourceStateValid, client.GetDataSourceStatus(t).State)
+
+		listener := NewDataSourceStatusListener(t, client, "status-listener")
+
+		request1 := endpoint.RequireConnection(t, incomingConnectionTimeout)
+		request1.Cancel()
+
+		interrupted := listener.ExpectState(t, servicedef.DataSourceStateInterrupted)
+		assert.True(t, interrupted.LastError.IsDefined(), "INTERRUPTED status should include last error")
+
+		_ = listener.ExpectState(t, servicedef.DataSourceStateValid)
+		assert.Equal(t, servicedef.DataSourceStateValid, client.GetDataSourceStatus(t).State)
+	})
+
+	t.Run("listener is notified of OFF after unrecoverable error", func(t *ldtest.T) {
+		// SDKs that conform to the RETRY specification keep retrying after a 401, so the data
+		// source never reaches the OFF state in this scenario.
+		if t.Capabilities().Has(servicedef.CapabilityRetryConformanceFDv1Streaming) {
+			t.SkipWithReason("SDK reports " + servicedef.CapabilityRetryConformanceFDv1Streaming +
+				"; legacy permanent-stop behavior does not apply")
+			return
+		}
+
+		stream := NewSDKDataSourceWithoutEndpoint(t, data, DataSourceOptionStreaming())
+		client, endpoint := makeClientWithStreamHandlers(t, false,
+			stream.Handler(),                   // first request succeeds
+			httphelpers.HandlerWithStatus(401), // second request gets an unrecoverable error
+		)
+		require.Equal(t, servicedef.DataSourceStateValid, client.GetDataSourceStatus(t).State)
+
+		listener := NewDataSourceStatusListener(t, client, "status-listener")
+
+		request1 := endpoint.RequireConnection(t, incomingConnectionTimeout)
+		request1.Cancel()
+
+		off := listener.ExpectState(t, servicedef.DataSourceStateOff)
+		if assert.True(t, off.LastError.IsDefined(), "OFF status should include last error") {
+			assert.Equal(t, servicedef.DataSourceErrorKindErrorResponse, off.LastError.Value().Kind)
+			assert.Equal(t, o.Some(401), off.LastError.Value().StatusCode)
+		}
+		assert.Equal(t, servicedef.DataSourceStateOff, client.GetDataSourceStatus(t).State)
+	})
+
+	t.Run("listener is not notified after it is unregistered", func(t *ldtest.T) {
+		stream := NewSDKDataSourceWithoutEndpoint(t, data, DataSourceOptionStreaming())
+		client, endpoint := makeClientWithStreamHandlers(t, false,
+			stream.Handler(),
+			httphelpers.HandlerWithStatus(503),
+			stream.Handler(),
+		)
+
+		listener := NewDataSourceStatusListener(t, client, "status-listener")
+		listener.Unregister(t)
+
+		request1 := endpoint.RequireConnection(t, incomingConnectionTimeout)
+		request1.Cancel()
+		_ = endpoint.RequireConnection(t, incomingConnectionTimeout)
+		_ = endpoint.RequireConnection(t, incomingConnectionTimeout)
+
+		listener.ExpectNoStatusChanges(t)
+	})
+}
+
+func doServerSideFlagChangeListenerTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityFlagChangeListeners)
+
+	flagKey := "flag"
+	flagV1, flagV2 := makeFlagVersionsWithValues(flagKey, 1, 2, ldvalue.String("a"), ldvalue.String("b"))
+
+	t.Run("notified when flag is updated", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV1).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+
+		listener.ExpectChanges(t, flagKey)
+	})
+
+	t.Run("notified when flag is added", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.EmptyServerSDKData(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV1))
+
+		listener.ExpectChanges(t, flagKey)
+	})
+
+	t.Run("notified when flag is deleted", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV1).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushDelete("flags", flagKey, 2)
+
+		listener.ExpectChanges(t, flagKey)
+	})
+
+	t.Run("not notified of update with same version", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV2).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+
+		listener.ExpectNoChanges(t)
+	})
+
+	t.Run("notified for flag whose prerequisite changed", func(t *ldtest.T) {
+		dependentFlag := ldbuilders.NewFlagBuilder("dependent").Version(1).
+			On(true).FallthroughVariation(0).OffVariation(1).Variations(ldvalue.Bool(true), ldvalue.Bool(false)).
+			AddPrerequisite(flagKey, 0).
+			Build()
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV1, dependentFlag).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+
+		listener.ExpectChanges(t, flagKey, dependentFlag.Key)
+	})
+
+	t.Run("notified for flag whose segment changed", func(t *ldtest.T) {
+		segmentKey := "segment"
+		segmentFlag := makeFlagToCheckSegmentMatch("segment-flag", segmentKey, ldvalue.Bool(false), ldvalue.Bool(true))
+		segmentV1 := ldbuilders.NewSegmentBuilder(segmentKey).Version(1).Build()
+		segmentV2 := ldbuilders.NewSegmentBuilder(segmentKey).Version(2).Included("user-key").Build()
+		dataSource := NewSDKDataSource(t,
+			mockld.NewServerSDKDataBuilder().Flag(segmentFlag).Segment(segmentV1).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+
+		dataSource.StreamingService().PushUpdate("segments", segmentKey, jsonhelpers.ToJSON(segmentV2))
+
+		listener.ExpectChanges(t, segmentFlag.Key)
+	})
+
+	t.Run("each registered listener is notified", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV1).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener1 := NewFlagChangeListener(t, client, "flag-listener-1")
+		listener2 := NewFlagChangeListener(t, client, "flag-listener-2")
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+
+		listener1.ExpectChanges(t, flagKey)
+		listener2.ExpectChanges(t, flagKey)
+	})
+
+	t.Run("not notified after listener is unregistered", func(t *ldtest.T) {
+		dataSource := NewSDKDataSource(t, mockld.NewServerSDKDataBuilder().Flag(flagV1).Build(),
+			DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+		listener := NewFlagChangeListener(t, client, "flag-listener")
+		listener.Unregister(t)
+
+		dataSource.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+
+		listener.ExpectNoChanges(t)
+	})
+}
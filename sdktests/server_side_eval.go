@@ -2,7 +2,6 @@ package sdktests
 
 import (
 	"github.com/launchdarkly/sdk-test-harness/v2/data"
-	"github.com/launchdarkly/sdk-test-harness/v2/data/testmodel"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
 	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
 	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
@@ -23,7 +22,7 @@ func doServerSideEvalTests(t *ldtest.T) {
 
 func runParameterizedServerSideEvalTests(t *ldtest.T) {
 	parameterizedTests := CommonEvalParameterizedTestRunner[mockld.ServerSDKData]{
-		SDKConfigurers:       func(testSuite testmodel.EvalTestSuite[mockld.ServerSDKData]) []SDKConfigurer { return nil },
+		SDKConfigurers:       nil,
 		FilterSDKData:        nil,
 		FilterExpectedReason: nil,
 	}
@@ -23,7 +23,10 @@ func doServerSideEventTests(t *ldtest.T) {
 	t.Run("index events", doServerSideIndexEventTests)
 	t.Run("context properties", doServerSideEventContextTests)
 	t.Run("event capacity", doServerSideEventBufferTests)
+	t.Run("diagnostic-events", doServerSideDiagnosticEventTests)
 	t.Run("disabling", doServerSideEventDisableTest)
+	t.Run("delivery", doServerSideEventDeliveryTests)
+	t.Run("close behavior", doServerSideEventCloseTests)
 }
 
 func doServerSideEventRequestTests(t *ldtest.T) {
@@ -106,3 +109,18 @@ func doServerSideEventDisableTest(t *ldtest.T) {
 	NewCommonEventTests(t, "doServerSideEventDisableTest").
 		DisablingEvents(t)
 }
+
+func doServerSideEventDeliveryTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doServerSideEventDeliveryTests").
+		Delivery(t)
+}
+
+func doServerSideEventCloseTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doServerSideEventCloseTests").
+		CloseBehavior(t)
+}
+
+func doServerSideDiagnosticEventTests(t *ldtest.T) {
+	NewCommonEventTests(t, "doServerSideDiagnosticEventTests").
+		DiagnosticEvents(t)
+}
@@ -0,0 +1,245 @@
+package sdktests
+
+import (
+	"time"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	"github.com/launchdarkly/go-server-sdk-evaluation/v3/ldbuilders"
+	"github.com/launchdarkly/go-test-helpers/v2/jsonhelpers"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doServerSideFDv2Tests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityFDv2)
+
+	t.Run("streaming", func(t *ldtest.T) {
+		t.Run("initialization", doServerSideFDv2StreamInitTests)
+		t.Run("incremental changes", doServerSideFDv2StreamChangeTests)
+		t.Run("selector resumption", doServerSideFDv2StreamResumptionTests)
+		t.Run("goodbye", doServerSideFDv2StreamGoodbyeTests)
+	})
+	t.Run("polling", func(t *ldtest.T) {
+		t.Run("initialization", doServerSideFDv2PollInitTests)
+		t.Run("incremental changes", doServerSideFDv2PollChangeTests)
+	})
+}
+
+const fdv2FlagKey = "flag-key"
+
+var fdv2Context = ldcontext.New("context-key") //nolint:gochecknoglobals
+
+func makeFDv2FlagData(version int, value ldvalue.Value) ([]byte, mockld.ServerSDKData) {
+	flag := ldbuilders.NewFlagBuilder(fdv2FlagKey).Version(version).
+		On(false).OffVariation(0).Variations(value).Build()
+	return jsonhelpers.ToJSON(flag), mockld.NewServerSDKDataBuilder().Flag(flag).Build()
+}
+
+func doServerSideFDv2StreamInitTests(t *ldtest.T) {
+	t.Run("evaluates flags from full transfer", func(t *ldtest.T) {
+		value := ldvalue.String("value")
+		_, data := makeFDv2FlagData(1, value)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+
+		request := dataSource.Endpoint().RequireConnection(t, time.Second)
+		assert.Equal(t, mockld.StreamingPathFDv2, request.URL.Path)
+		assert.Empty(t, request.URL.Query().Get(mockld.FDv2BasisQueryParam),
+			"SDK should not send a basis when it has no data")
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, ldvalue.Null())
+		m.In(t).Assert(actual, m.JSONEqual(value))
+	})
+
+	t.Run("segments are applied", func(t *ldtest.T) {
+		segmentKey := "segment-key"
+		valueIfIncluded, valueIfNotIncluded := ldvalue.String("included"), ldvalue.String("not included")
+		segment := ldbuilders.NewSegmentBuilder(segmentKey).Version(1).Included(fdv2Context.Key()).Build()
+		flag := makeFlagToCheckSegmentMatch(fdv2FlagKey, segmentKey, valueIfNotIncluded, valueIfIncluded)
+		data := mockld.NewServerSDKDataBuilder().Flag(flag).Segment(segment).Build()
+
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, ldvalue.Null())
+		m.In(t).Assert(actual, m.JSONEqual(valueIfIncluded))
+	})
+}
+
+func doServerSideFDv2StreamChangeTests(t *ldtest.T) {
+	valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+	defaultValue := ldvalue.String("default")
+
+	t.Run("put-object is applied", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+
+		flagAfter, _ := makeFDv2FlagData(2, valueAfter)
+		dataSource.FDv2StreamingService().PushUpdate("flags", fdv2FlagKey, 2, flagAfter)
+
+		pollUntilFlagValueUpdated(t, client, fdv2FlagKey, fdv2Context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("delete-object is applied", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+
+		dataSource.FDv2StreamingService().PushDelete("flags", fdv2FlagKey, 2)
+
+		pollUntilFlagValueUpdated(t, client, fdv2FlagKey, fdv2Context, valueBefore, defaultValue, defaultValue)
+	})
+
+	t.Run("full transfer replaces all data", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, dataSource)
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+
+		otherFlag := ldbuilders.NewFlagBuilder("other-flag").Version(1).Build()
+		dataSource.FDv2StreamingService().PushFullTransfer(
+			mockld.NewServerSDKDataBuilder().Flag(otherFlag).Build())
+
+		pollUntilFlagValueUpdated(t, client, fdv2FlagKey, fdv2Context, valueBefore, defaultValue, defaultValue)
+	})
+}
+
+func doServerSideFDv2StreamResumptionTests(t *ldtest.T) {
+	valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+	defaultValue := ldvalue.String("default")
+
+	streamConfig := WithStreamingConfig(servicedef.SDKConfigStreamingParams{
+		InitialRetryDelayMS: o.Some(briefDelay),
+	})
+
+	t.Run("reconnects with basis from last payload-transferred", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		_ = NewSDKClient(t, streamConfig, dataSource)
+
+		request1 := dataSource.Endpoint().RequireConnection(t, time.Second)
+		request1.Cancel()
+
+		request2 := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+		assert.Equal(t, dataSource.FDv2Payload().Selector(),
+			request2.URL.Query().Get(mockld.FDv2BasisQueryParam))
+	})
+
+	t.Run("receives changes that were missed while disconnected", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, streamConfig, dataSource)
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+
+		request1 := dataSource.Endpoint().RequireConnection(t, time.Second)
+		basisBefore := dataSource.FDv2Payload().Selector()
+
+		// Apply the change directly to the payload, rather than pushing it on the stream, so that the
+		// SDK can only find out about it by resuming from its previous selector.
+		flagAfter, _ := makeFDv2FlagData(2, valueAfter)
+		dataSource.FDv2Payload().ApplyChanges(mockld.FDv2Put("flags", fdv2FlagKey, 2, flagAfter))
+		request1.Cancel()
+
+		request2 := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+		assert.Equal(t, basisBefore, request2.URL.Query().Get(mockld.FDv2BasisQueryParam))
+
+		pollUntilFlagValueUpdated(t, client, fdv2FlagKey, fdv2Context, valueBefore, valueAfter, defaultValue)
+	})
+
+	t.Run("keeps data when already up to date", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		client := NewSDKClient(t, streamConfig, dataSource)
+
+		request1 := dataSource.Endpoint().RequireConnection(t, time.Second)
+		request1.Cancel()
+		request2 := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+		require.Equal(t, dataSource.FDv2Payload().Selector(),
+			request2.URL.Query().Get(mockld.FDv2BasisQueryParam))
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+	})
+}
+
+func doServerSideFDv2StreamGoodbyeTests(t *ldtest.T) {
+	t.Run("reconnects after goodbye", func(t *ldtest.T) {
+		_, data := makeFDv2FlagData(1, ldvalue.String("value"))
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionStreaming())
+		_ = NewSDKClient(t, WithStreamingConfig(servicedef.SDKConfigStreamingParams{
+			InitialRetryDelayMS: o.Some(briefDelay),
+		}), dataSource)
+
+		_ = dataSource.Endpoint().RequireConnection(t, time.Second)
+		dataSource.FDv2StreamingService().PushGoodbye("service restarting")
+
+		request2 := dataSource.Endpoint().RequireConnection(t, time.Second*5)
+		assert.Equal(t, dataSource.FDv2Payload().Selector(),
+			request2.URL.Query().Get(mockld.FDv2BasisQueryParam))
+	})
+}
+
+func doServerSideFDv2PollInitTests(t *ldtest.T) {
+	t.Run("evaluates flags from poll response", func(t *ldtest.T) {
+		value := ldvalue.String("value")
+		_, data := makeFDv2FlagData(1, value)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionPolling())
+		client := NewSDKClient(t, dataSource)
+
+		request := dataSource.Endpoint().RequireConnection(t, time.Second)
+		assert.Equal(t, mockld.PollingPathFDv2, request.URL.Path)
+		assert.Empty(t, request.URL.Query().Get(mockld.FDv2BasisQueryParam),
+			"SDK should not send a basis when it has no data")
+
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, ldvalue.Null())
+		m.In(t).Assert(actual, m.JSONEqual(value))
+	})
+}
+
+func doServerSideFDv2PollChangeTests(t *ldtest.T) {
+	t.Run("next poll sends basis and applies changes", func(t *ldtest.T) {
+		// Server-side SDKs enforce a minimum poll interval of 30 seconds, so we can't make this fast.
+		t.LongRunning()
+		pollInterval := time.Second * 30
+
+		valueBefore, valueAfter := ldvalue.String("valueBefore"), ldvalue.String("valueAfter")
+		defaultValue := ldvalue.String("default")
+		_, data := makeFDv2FlagData(1, valueBefore)
+		dataSource := NewSDKDataSource(t, data, DataSourceOptionFDv2(), DataSourceOptionPolling())
+		client := NewSDKClient(t, WithPollingConfig(servicedef.SDKConfigPollingParams{
+			PollIntervalMS: o.Some(ldtime.UnixMillisecondTime(pollInterval.Milliseconds())),
+		}), dataSource)
+
+		_ = dataSource.Endpoint().RequireConnection(t, time.Second)
+		actual := basicEvaluateFlag(t, client, fdv2FlagKey, fdv2Context, defaultValue)
+		m.In(t).Assert(actual, m.JSONEqual(valueBefore))
+
+		basisBefore := dataSource.FDv2Payload().Selector()
+		flagAfter, _ := makeFDv2FlagData(2, valueAfter)
+		dataSource.FDv2Payload().ApplyChanges(mockld.FDv2Put("flags", fdv2FlagKey, 2, flagAfter))
+
+		request2 := dataSource.Endpoint().RequireConnection(t, pollInterval+time.Second*10)
+		assert.Equal(t, basisBefore, request2.URL.Query().Get(mockld.FDv2BasisQueryParam))
+
+		pollUntilFlagValueUpdated(t, client, fdv2FlagKey, fdv2Context, valueBefore, valueAfter, defaultValue)
+	})
+}
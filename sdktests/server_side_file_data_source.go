@@ -0,0 +1,109 @@
+package sdktests
+
+import (
+	"time"
+
+	h "github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	"github.com/launchdarkly/go-server-sdk-evaluation/v3/ldbuilders"
+	"github.com/launchdarkly/go-server-sdk-evaluation/v3/ldmodel"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+)
+
+func doServerSideFileDataSourceTests(t *ldtest.T) {
+	t.RequireCapability(servicedef.CapabilityFileDataSource)
+
+	context := ldcontext.New("user-key")
+	defaultValue := ldvalue.String("default")
+	value1, value2 := ldvalue.String("value1"), ldvalue.String("value2")
+
+	makeFlag := func(key string, value ldvalue.Value) ldmodel.FeatureFlag {
+		return ldbuilders.NewFlagBuilder(key).Version(1).
+			On(false).OffVariation(0).Variations(value, ldvalue.String("other")).
+			Build()
+	}
+	makeData := func(flags ...ldmodel.FeatureFlag) mockld.ServerSDKData {
+		return mockld.NewServerSDKDataBuilder().Flag(flags...).Build()
+	}
+	requireValue := func(t *ldtest.T, client *SDKClient, flagKey string, expected ldvalue.Value) {
+		m.In(t).Require(basicEvaluateFlag(t, client, flagKey, context, defaultValue), m.JSONEqual(expected))
+	}
+
+	t.Run("initial load", func(t *ldtest.T) {
+		data := makeData(makeFlag("flag1", value1), makeFlag("flag2", value2))
+
+		t.Run("JSON", func(t *ldtest.T) {
+			files := NewSDKFileDataSource(t, false)
+			files.WriteFile(t, "flags.json", fileDataJSON(data))
+			client := NewSDKClient(t, files)
+			requireValue(t, client, "flag1", value1)
+			requireValue(t, client, "flag2", value2)
+		})
+
+		t.Run("YAML", func(t *ldtest.T) {
+			files := NewSDKFileDataSource(t, false)
+			files.WriteFile(t, "flags.yml", fileDataYAML(t, data))
+			client := NewSDKClient(t, files)
+			requireValue(t, client, "flag1", value1)
+			requireValue(t, client, "flag2", value2)
+		})
+	})
+
+	t.Run("simplified flagValues syntax", func(t *ldtest.T) {
+		files := NewSDKFileDataSource(t, false)
+		files.WriteFile(t, "flags.json", fileDataFlagValues(map[string]ldvalue.Value{
+			"flag1": value1,
+			"flag2": ldvalue.ObjectBuild().Set("a", ldvalue.Int(1)).Build(),
+		}))
+		client := NewSDKClient(t, files)
+		requireValue(t, client, "flag1", value1)
+		requireValue(t, client, "flag2", ldvalue.ObjectBuild().Set("a", ldvalue.Int(1)).Build())
+	})
+
+	t.Run("multiple files", func(t *ldtest.T) {
+		files := NewSDKFileDataSource(t, false)
+		files.WriteFile(t, "flags1.json", fileDataJSON(makeData(makeFlag("flag1", value1))))
+		files.WriteFile(t, "flags2.json", fileDataFlagValues(map[string]ldvalue.Value{"flag2": value2}))
+		client := NewSDKClient(t, files)
+		requireValue(t, client, "flag1", value1)
+		requireValue(t, client, "flag2", value2)
+	})
+
+	t.Run("duplicate key across files is an error", func(t *ldtest.T) {
+		// A flag key that appears in more than one file makes the whole data set invalid, so the SDK
+		// should not have loaded any flags at all-- not even the ones that were not duplicated.
+		files := NewSDKFileDataSource(t, false)
+		files.WriteFile(t, "flags1.json", fileDataJSON(makeData(makeFlag("flag1", value1), makeFlag("flag2", value1))))
+		files.WriteFile(t, "flags2.json", fileDataJSON(makeData(makeFlag("flag1", value2))))
+		client := NewSDKClient(t,
+			WithConfig(servicedef.SDKConfigParams{
+				StartWaitTimeMS: o.Some(ldtime.UnixMillisecondTime(1000)),
+				InitCanFail:     true,
+			}),
+			files)
+		requireValue(t, client, "flag1", defaultValue)
+		requireValue(t, client, "flag2", defaultValue)
+	})
+
+	t.Run("auto-update reloads a file that is rewritten", func(t *ldtest.T) {
+		files := NewSDKFileDataSource(t, true)
+		files.WriteFile(t, "flags.json", fileDataJSON(makeData(makeFlag("flag1", value1))))
+		client := NewSDKClient(t, files)
+		requireValue(t, client, "flag1", value1)
+
+		files.WriteFile(t, "flags.json", fileDataJSON(makeData(makeFlag("flag1", value2))))
+
+		// File watching mechanisms can be slow on some platforms, so we allow more time than we would
+		// for a stream update.
+		h.RequireEventually(t,
+			checkForUpdatedValue(t, client, "flag1", context, value1, value2, defaultValue),
+			time.Second*10, time.Millisecond*100, "timed out without seeing updated flag value")
+	})
+}
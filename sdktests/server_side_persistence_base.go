@@ -63,7 +63,8 @@ func doServerSidePersistentTests(t *ldtest.T) {
 		consul, err := consul.NewClient(config)
 		require.NoError(t, err)
 
-		t.Run("consul", newServerSidePersistentTests(t, &ConsulPersistentStore{consul: consul}, "launchdarkly").Run)
+		store := &ConsulPersistentStore{consul: consul, address: config.Address}
+		t.Run("consul", newServerSidePersistentTests(t, store, "launchdarkly").Run)
 	}
 
 	if t.Capabilities().Has(servicedef.CapabilityPersistentDataStoreDynamoDB) {
@@ -104,6 +105,13 @@ type PersistentStore interface {
 	Type() servicedef.SDKConfigPersistentType
 
 	Reset() error
+
+	// Address returns the "host:port" address of the store, as seen from the test harness.
+	Address() string
+
+	// DSNForAddress returns a DSN like the one from DSN(), but for a store at a different "host:port"
+	// address. The outage tests use this to make the SDK connect through a TCP forwarder.
+	DSNForAddress(address string) string
 }
 
 type ServerSidePersistentTests struct {
@@ -629,6 +637,8 @@ func (s *ServerSidePersistentTests) Run(t *ldtest.T) {
 			})
 		}
 	})
+
+	t.Run("outage", s.runOutageTests)
 }
 
 func (s *ServerSidePersistentTests) runWithEmptyStore(t *ldtest.T, testName string, action func(*ldtest.T)) {
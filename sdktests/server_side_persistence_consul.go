@@ -11,7 +11,8 @@ import (
 )
 
 type ConsulPersistentStore struct {
-	consul *consul.Client
+	consul  *consul.Client
+	address string
 }
 
 func (c *ConsulPersistentStore) DSN() string {
@@ -20,6 +21,14 @@ func (c *ConsulPersistentStore) DSN() string {
 	return consul.DefaultConfig().Address
 }
 
+func (c *ConsulPersistentStore) Address() string {
+	return c.address
+}
+
+func (c *ConsulPersistentStore) DSNForAddress(address string) string {
+	return address
+}
+
 func (c *ConsulPersistentStore) Type() servicedef.SDKConfigPersistentType {
 	return servicedef.Consul
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -34,6 +35,14 @@ func (d *DynamoDBPersistentStore) DSN() string {
 	return d.endpoint
 }
 
+func (d *DynamoDBPersistentStore) Address() string {
+	return strings.TrimPrefix(d.endpoint, "http://")
+}
+
+func (d *DynamoDBPersistentStore) DSNForAddress(address string) string {
+	return "http://" + address
+}
+
 func (d *DynamoDBPersistentStore) Type() servicedef.SDKConfigPersistentType {
 	return servicedef.DynamoDB
 }
@@ -0,0 +1,118 @@
+package sdktests
+
+import (
+	"time"
+
+	h "github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SDKs check whether a failed store has come back at their own interval, so we allow much more time
+// for recovery than for other persistence operations.
+const persistentStoreRecoveryTimeout = time.Second * 10
+
+func (s *ServerSidePersistentTests) runOutageTests(t *ldtest.T) {
+	context := ldcontext.New("user-key")
+	defaultValue := ldvalue.String("default")
+
+	// In these tests only, the SDK connects to the store through a forwarder that we can disable to
+	// simulate an outage. The forwarder listens on the local host, so these tests will fail if the test
+	// service runs on a different host from the test harness.
+	forwarder, err := mockld.NewTCPForwarder(s.persistentStore.Address())
+	require.NoError(t, err)
+	t.Defer(forwarder.Close)
+
+	startClient := func(t *ldtest.T, cache servicedef.SDKConfigPersistentCache) (*SDKDataSource, *SDKClient) {
+		persistence := NewPersistence()
+		persistence.SetStore(servicedef.SDKConfigPersistentStore{
+			Type: s.persistentStore.Type(),
+			DSN:  s.persistentStore.DSNForAddress(forwarder.Addr()),
+		})
+		persistence.SetCache(cache)
+
+		sdkData := s.makeSDKDataWithFlag("flag-key", 1, ldvalue.String("value"))
+		stream, configurers := s.setupDataSources(t, sdkData)
+		configurers = append(configurers, persistence)
+
+		client := NewSDKClient(t, s.baseSDKConfigurationPlus(configurers...)...)
+		s.eventuallyRequireDataStoreInit(t, s.defaultPrefix)
+		pollUntilFlagValueUpdated(t, client, "flag-key", context, defaultValue, ldvalue.String("value"), defaultValue)
+		return stream, client
+	}
+
+	// Every test starts an outage, so we make sure the store is available again for whatever runs next.
+	startOutage := func(t *ldtest.T) {
+		forwarder.SetAvailable(false)
+		t.Defer(func() { forwarder.SetAvailable(true) })
+	}
+
+	s.runWithEmptyStore(t, "cached flags are served during outage", func(t *ldtest.T) {
+		_, client := startClient(t, servicedef.SDKConfigPersistentCache{Mode: servicedef.CacheModeInfinite})
+
+		startOutage(t)
+
+		h.RequireNever(t,
+			checkForUpdatedValue(t, client, "flag-key", context,
+				ldvalue.String("value"), defaultValue, defaultValue),
+			time.Millisecond*500, time.Millisecond*20, "flag value was not served from cache during outage")
+	})
+
+	s.runWithEmptyStore(t, "data store status reports outage and recovery", func(t *ldtest.T) {
+		t.RequireCapability(servicedef.CapabilityDataStoreStatus)
+
+		stream, client := startClient(t, servicedef.SDKConfigPersistentCache{Mode: servicedef.CacheModeOff})
+		require.True(t, client.GetDataStoreStatus(t).Available, "data store should be available before outage")
+
+		startOutage(t)
+
+		// The SDK won't notice the outage until it tries to use the store, so we give it an update to
+		// write and also make it read from the store.
+		stream.StreamingService().PushUpdate("flags", "flag-key",
+			s.makeFlagData("flag-key", 2, ldvalue.String("new-value")))
+		h.RequireEventually(t, func() bool {
+			_ = basicEvaluateFlag(t, client, "flag-key", context, defaultValue)
+			return !client.GetDataStoreStatus(t).Available
+		}, time.Second*5, time.Millisecond*100, "data store status did not report outage")
+
+		forwarder.SetAvailable(true)
+
+		h.RequireEventually(t, func() bool {
+			return client.GetDataStoreStatus(t).Available
+		}, persistentStoreRecoveryTimeout, time.Millisecond*100, "data store status did not report recovery")
+	})
+
+	s.runWithEmptyStore(t, "SDK rewrites full data set after recovery with infinite cache", func(t *ldtest.T) {
+		stream, client := startClient(t, servicedef.SDKConfigPersistentCache{Mode: servicedef.CacheModeInfinite})
+
+		startOutage(t)
+
+		// This update can't be written to the store yet, but the SDK should keep it in its cache.
+		stream.StreamingService().PushUpdate("flags", "flag-key",
+			s.makeFlagData("flag-key", 2, ldvalue.String("new-value")))
+		pollUntilFlagValueUpdated(t, client, "flag-key", context,
+			ldvalue.String("value"), ldvalue.String("new-value"), defaultValue)
+
+		// Simulate the store having lost its data during the outage, so that we can tell whether the
+		// SDK really rewrites everything rather than just retrying the failed update.
+		require.NoError(t, s.persistentStore.Reset())
+
+		forwarder.SetAvailable(true)
+
+		matchers := map[string]m.Matcher{
+			"flag-key": basicFlagValidationMatcher("flag-key", 2, "new-value"),
+		}
+		h.RequireEventually(t, func() bool {
+			data, err := s.persistentStore.GetMap(s.defaultPrefix, "features")
+			return err == nil && validateFlagData(data, matchers)
+		}, persistentStoreRecoveryTimeout, time.Millisecond*100, "SDK did not rewrite its data to the store")
+		s.eventuallyRequireDataStoreInit(t, s.defaultPrefix)
+	})
+}
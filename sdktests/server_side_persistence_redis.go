@@ -18,6 +18,14 @@ func (r *RedisPersistentStore) DSN() string {
 	return fmt.Sprintf("redis://%s", r.redis.Options().Addr)
 }
 
+func (r *RedisPersistentStore) Address() string {
+	return r.redis.Options().Addr
+}
+
+func (r *RedisPersistentStore) DSNForAddress(address string) string {
+	return fmt.Sprintf("redis://%s", address)
+}
+
 func (r *RedisPersistentStore) Type() servicedef.SDKConfigPersistentType {
 	return servicedef.Redis
 }
@@ -39,6 +39,7 @@ func doServerSidePollRetryTests(t *ldtest.T) {
 	// SDK production defaults that's max(5 min, 30 s) = 5 min.  10 seconds extra gives
 	// margin over the 5-min ceiling.
 	extendedRegimePollTimeout := 5*time.Minute + 10*time.Second
+	t.Timeout(extendedRegimePollTimeout + time.Minute)
 
 	// initialPollTimeout bounds the SDK's initial poll (no backoff involved; happens on client
 	// construction).
@@ -19,6 +19,7 @@ func doServerSideStreamTests(t *ldtest.T) {
 	t.Run("retry behavior", doServerSideStreamRetryTests)
 	t.Run("validation", doServerSideStreamValidationTests)
 	t.Run("connection lifecycle", doServerSideStreamConnectionLifecycleTests)
+	t.Run("stall detection", NewCommonStreamingTests(t, "doServerSideStreamStallTests").StallDetection)
 }
 
 func doServerSideStreamRequestTests(t *ldtest.T) {
@@ -16,7 +16,10 @@ import (
 	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
 
 	"github.com/launchdarkly/go-test-helpers/v2/httphelpers"
+	"github.com/launchdarkly/go-test-helpers/v2/jsonhelpers"
 	m "github.com/launchdarkly/go-test-helpers/v2/matchers"
+
+	"github.com/stretchr/testify/assert"
 )
 
 const briefDelay ldtime.UnixMillisecondTime = 1
@@ -51,9 +54,13 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 	// reasonable chance of detecting an inappropriate retry that happened promptly.
 	noMoreConnectionsTimeout := time.Millisecond * 100
 
-	makeStreamEndpoint := func(t *ldtest.T, handler http.Handler) *harness.MockEndpoint {
+	makeStreamEndpoint := func(
+		t *ldtest.T,
+		handler http.Handler,
+		options ...harness.MockEndpointOption,
+	) *harness.MockEndpoint {
 		return requireContext(t).harness.NewMockEndpoint(handler, t.DebugLogger(),
-			harness.MockEndpointDescription("streaming service"))
+			append([]harness.MockEndpointOption{harness.MockEndpointDescription("streaming service")}, options...)...)
 	}
 
 	t.Run("retry after stream is closed", func(t *ldtest.T) {
@@ -152,6 +159,34 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 		}
 	})
 
+	shouldRetryAfterFaultOnInitialConnect := func(t *ldtest.T, fault harness.Fault) {
+		stream := NewSDKDataSourceWithoutEndpoint(t, dataV1)
+		streamEndpoint := makeStreamEndpoint(t, stream.Handler(),
+			harness.MockEndpointFaultForRequest(0, fault), // first request fails partway through the data
+			harness.MockEndpointFaultForRequest(1, fault), // second request also fails
+		) // third request succeeds and gets the stream
+		t.Defer(streamEndpoint.Close)
+
+		client := NewSDKClient(t, WithStreamingConfig(baseStreamConfig(streamEndpoint)))
+		result := client.EvaluateAllFlags(t, servicedef.EvaluateAllFlagsParams{Context: o.Some(context)})
+		m.In(t).Assert(result, EvalAllFlagsValueForKeyShouldEqual(flagKey, expectedValueV1))
+
+		for i := 0; i < 3; i++ { // expect three requests
+			_ = streamEndpoint.RequireConnection(t, incomingConnectionTimeout)
+		}
+
+		streamEndpoint.RequireNoMoreConnections(t, noMoreConnectionsTimeout)
+	}
+
+	// The put event is much longer than 10 bytes, so these faults always interrupt it.
+	t.Run("retry after connection is reset during initial data", func(t *ldtest.T) {
+		shouldRetryAfterFaultOnInitialConnect(t, harness.FaultResetAfterBytes(10))
+	})
+
+	t.Run("retry after stream is truncated during initial data", func(t *ldtest.T) {
+		shouldRetryAfterFaultOnInitialConnect(t, harness.FaultTruncateAfterBytes(10))
+	})
+
 	shouldRetryAfterErrorOnReconnect := func(t *ldtest.T, errorHandler http.Handler) {
 		stream1 := NewSDKDataSourceWithoutEndpoint(t, dataV1)
 		stream2 := NewSDKDataSourceWithoutEndpoint(t, dataV2)
@@ -199,6 +234,54 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 		}
 	})
 
+	t.Run("Last-Event-ID", func(t *ldtest.T) {
+		t.RequireCapability(servicedef.CapabilityStreamingLastEventID)
+
+		// The full data set is only changed to this after the SDK has received the first two versions
+		// of the flag, so if the SDK ever gets this value, the stream was not resumed.
+		expectedValueV3 := ldvalue.Int(3)
+		fullDataValue := ldvalue.Int(4)
+		flagV3, flagFullData := makeFlagVersionsWithValues(flagKey, 3, 4, expectedValueV3, fullDataValue)
+		fullData := mockld.NewServerSDKDataBuilder().Flag(flagFullData).Build()
+
+		t.Run("sends ID of last event received when reconnecting", func(t *ldtest.T) {
+			stream := NewSDKDataSource(t, dataV1, DataSourceOptionEventIDs())
+			client := NewSDKClient(t, WithStreamingConfig(baseStreamConfig(stream.Endpoint())))
+
+			request1 := stream.Endpoint().RequireConnection(t, incomingConnectionTimeout)
+			assert.Equal(t, "", request1.Headers.Get("Last-Event-ID"))
+
+			stream.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+			pollUntilFlagValueUpdated(t, client, flagKey, context, expectedValueV1, expectedValueV2, ldvalue.Null())
+
+			// The put event had the ID "1", and the patch event had the ID "2".
+			request1.Cancel()
+			request2 := stream.Endpoint().RequireConnection(t, incomingConnectionTimeout)
+			assert.Equal(t, "2", request2.Headers.Get("Last-Event-ID"))
+		})
+
+		t.Run("applies events replayed after reconnecting", func(t *ldtest.T) {
+			stream := NewSDKDataSource(t, dataV1, DataSourceOptionPartialReplay())
+			client := NewSDKClient(t, WithStreamingConfig(baseStreamConfig(stream.Endpoint())))
+
+			request1 := stream.Endpoint().RequireConnection(t, incomingConnectionTimeout)
+			stream.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV2))
+			pollUntilFlagValueUpdated(t, client, flagKey, context, expectedValueV1, expectedValueV2, ldvalue.Null())
+
+			// Stall the connection so that the SDK misses the next event, then drop it.
+			stream.StreamingService().StallActiveConnections()
+			stream.StreamingService().PushUpdate("flags", flagKey, jsonhelpers.ToJSON(flagV3))
+			stream.SetInitialData(fullData)
+			request1.Cancel()
+
+			request2 := stream.Endpoint().RequireConnection(t, incomingConnectionTimeout)
+			assert.Equal(t, "2", request2.Headers.Get("Last-Event-ID"))
+
+			// The service resends only the event that the SDK missed, rather than the full data set.
+			pollUntilFlagValueUpdated(t, client, flagKey, context, expectedValueV2, expectedValueV3, ldvalue.Null())
+		})
+	})
+
 	// extendedRegimeConnectionTimeout is the observation window for a reconnect during
 	// extended-regime backoff. The SDK's production default is a 5-minute extended-initial
 	// delay with up to 50% subtractive jitter, so the actual wait to the FIRST extended
@@ -212,6 +295,7 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 	t.Run("retry after unexpected HTTP error on initial connect", func(t *ldtest.T) {
 		t.RequireCapability(servicedef.CapabilityRetryConformanceFDv1Streaming)
 		t.LongRunning()
+		t.Timeout(extendedRegimeConnectionTimeout + time.Minute)
 		for _, status := range unexpectedErrors {
 			t.Run(fmt.Sprintf("error %d", status), func(t *ldtest.T) {
 				stream := NewSDKDataSourceWithoutEndpoint(t, dataV1)
@@ -244,6 +328,7 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 	t.Run("retry after unexpected HTTP error on reconnect", func(t *ldtest.T) {
 		t.RequireCapability(servicedef.CapabilityRetryConformanceFDv1Streaming)
 		t.LongRunning()
+		t.Timeout(extendedRegimeConnectionTimeout + time.Minute)
 		for _, status := range unexpectedErrors {
 			t.Run(fmt.Sprintf("error %d", status), func(t *ldtest.T) {
 				stream1 := NewSDKDataSourceWithoutEndpoint(t, dataV1)
@@ -277,6 +362,7 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 	t.Run("enters extended-regime backoff after unexpected HTTP error", func(t *ldtest.T) {
 		t.RequireCapability(servicedef.CapabilityRetryConformanceFDv1Streaming)
 		t.LongRunning()
+		t.Timeout(extendedRegimeConnectionTimeout + time.Minute)
 		stream := NewSDKDataSourceWithoutEndpoint(t, dataV1)
 		handler := httphelpers.SequentialHandler(
 			httphelpers.HandlerWithStatus(401), // 1st: unexpected error
@@ -306,6 +392,7 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 		// cadence. Under production timing, first extended retry is ~2.5-5 min after the fault.
 		t.RequireCapability(servicedef.CapabilityRetryConformanceFDv1Streaming)
 		t.LongRunning()
+		t.Timeout(extendedRegimeConnectionTimeout + time.Minute)
 		streamEndpoint := makeStreamEndpoint(t, httphelpers.HandlerWithStatus(401))
 		t.Defer(streamEndpoint.Close)
 
@@ -337,6 +424,7 @@ func doServerSideStreamRetryTests(t *ldtest.T) {
 		//   5. SDK should retry at NORMAL-regime timing (briefDelay), not extended (~5 min).
 		t.RequireCapability(servicedef.CapabilityRetryConformanceFDv1Streaming)
 		t.LongRunning()
+		t.Timeout(extendedRegimeConnectionTimeout + time.Minute)
 
 		stream1 := NewSDKDataSourceWithoutEndpoint(t, dataV1)
 		stream2 := NewSDKDataSourceWithoutEndpoint(t, dataV2)
@@ -0,0 +1,179 @@
+package sdktests
+
+import (
+	"sort"
+	"time"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listenerReceiveTimeout = time.Second * 5
+const listenerWaitForNoCallTimeout = time.Millisecond * 500
+
+// GetDataSourceStatus queries the data source status from the SDK client. The test harness will
+// only call this method if the test service has the "data-source-status" capability.
+func (c *SDKClient) GetDataSourceStatus(t *ldtest.T) servicedef.DataSourceStatus {
+	var resp servicedef.DataSourceStatus
+	require.NoError(t, c.sdkClientEntity.SendCommand(servicedef.CommandGetDataSourceStatus,
+		t.DebugLogger(), &resp))
+	return resp
+}
+
+func (c *SDKClient) registerListener(t *ldtest.T, command, listenerID, callbackURI string) {
+	require.NoError(t, c.sdkClientEntity.SendCommandWithParams(
+		servicedef.CommandParams{
+			Command: command,
+			RegisterListener: o.Some(servicedef.RegisterListenerParams{
+				ListenerID:  listenerID,
+				CallbackURI: callbackURI,
+			}),
+		},
+		t.DebugLogger(),
+		nil,
+	))
+}
+
+func (c *SDKClient) unregisterListener(t *ldtest.T, listenerID string) {
+	require.NoError(t, c.sdkClientEntity.SendCommandWithParams(
+		servicedef.CommandParams{
+			Command:            servicedef.CommandUnregisterListener,
+			UnregisterListener: o.Some(servicedef.UnregisterListenerParams{ListenerID: listenerID}),
+		},
+		t.DebugLogger(),
+		nil,
+	))
+}
+
+// FlagChangeListener is a flag change listener that the test service has registered with an SDK
+// client, which reports its notifications to a callback endpoint in the test harness.
+type FlagChangeListener struct {
+	id              string
+	client          *SDKClient
+	callbackService *mockld.ListenerCallbackService[servicedef.FlagChangeCallbackPayload]
+}
+
+// NewFlagChangeListener tells the test service to register a flag change listener with the SDK
+// client. The listener ID only needs to be unique among listeners registered with this client.
+// The callback endpoint is closed automatically at the end of the test.
+func NewFlagChangeListener(t *ldtest.T, client *SDKClient, listenerID string) *FlagChangeListener {
+	l := &FlagChangeListener{
+		id:     listenerID,
+		client: client,
+		callbackService: mockld.NewListenerCallbackService[servicedef.FlagChangeCallbackPayload](
+			requireContext(t).harness, t.DebugLogger(), "flag change listener"),
+	}
+	t.Defer(l.callbackService.Close)
+	client.registerListener(t, servicedef.CommandRegisterFlagChangeListener, listenerID,
+		l.callbackService.GetURL())
+	return l
+}
+
+// Unregister tells the test service to unregister the listener.
+func (l *FlagChangeListener) Unregister(t *ldtest.T) {
+	l.client.unregisterListener(t, l.id)
+}
+
+// ExpectChanges waits until the listener has been notified of a change to each of the specified
+// flags, in any order. Notifications for other flags are ignored. If any of the flags is not
+// reported before the timeout, the test fails and terminates.
+func (l *FlagChangeListener) ExpectChanges(t *ldtest.T, flagKeys ...string) {
+	remaining := make(map[string]bool)
+	for _, key := range flagKeys {
+		remaining[key] = true
+	}
+	deadline := time.Now().Add(listenerReceiveTimeout)
+	for len(remaining) > 0 {
+		maybePayload := helpers.TryReceive(l.callbackService.CallChannel, time.Until(deadline))
+		if !maybePayload.IsDefined() {
+			t.Errorf("Timed out waiting for flag change notifications; still expecting %v", keysOf(remaining))
+			t.FailNow()
+		}
+		payload := maybePayload.Value()
+		assert.Equal(t, l.id, payload.ListenerID)
+		delete(remaining, payload.FlagKey)
+	}
+}
+
+// ExpectNoChanges verifies that the listener does not receive any notifications within a short
+// interval.
+func (l *FlagChangeListener) ExpectNoChanges(t *ldtest.T) {
+	maybePayload := helpers.TryReceive(l.callbackService.CallChannel, listenerWaitForNoCallTimeout)
+	assert.False(t, maybePayload.IsDefined(), "Expected no flag change notifications, got one for %q",
+		maybePayload.Value().FlagKey)
+}
+
+// DataSourceStatusListener is a data source status listener that the test service has registered
+// with an SDK client, which reports its notifications to a callback endpoint in the test harness.
+type DataSourceStatusListener struct {
+	id              string
+	client          *SDKClient
+	callbackService *mockld.ListenerCallbackService[servicedef.DataSourceStatusCallbackPayload]
+}
+
+// NewDataSourceStatusListener tells the test service to register a data source status listener
+// with the SDK client. The listener ID only needs to be unique among listeners registered with this
+// client. The callback endpoint is closed automatically at the end of the test.
+func NewDataSourceStatusListener(t *ldtest.T, client *SDKClient, listenerID string) *DataSourceStatusListener {
+	l := &DataSourceStatusListener{
+		id:     listenerID,
+		client: client,
+		callbackService: mockld.NewListenerCallbackService[servicedef.DataSourceStatusCallbackPayload](
+			requireContext(t).harness, t.DebugLogger(), "data source status listener"),
+	}
+	t.Defer(l.callbackService.Close)
+	client.registerListener(t, servicedef.CommandRegisterDataSourceStatusListener, listenerID,
+		l.callbackService.GetURL())
+	return l
+}
+
+// Unregister tells the test service to unregister the listener.
+func (l *DataSourceStatusListener) Unregister(t *ldtest.T) {
+	l.client.unregisterListener(t, l.id)
+}
+
+// ExpectState waits until the listener reports a status with the specified state, and returns
+// that status. Notifications with other states are skipped, since an SDK may report the same
+// state more than once (for instance, each time a retry fails). If no such status is reported
+// before the timeout, the test fails and terminates.
+func (l *DataSourceStatusListener) ExpectState(
+	t *ldtest.T,
+	state servicedef.DataSourceState,
+) servicedef.DataSourceStatus {
+	deadline := time.Now().Add(listenerReceiveTimeout)
+	for {
+		maybePayload := helpers.TryReceive(l.callbackService.CallChannel, time.Until(deadline))
+		if !maybePayload.IsDefined() {
+			t.Errorf("Timed out waiting for data source status %s", state)
+			t.FailNow()
+		}
+		payload := maybePayload.Value()
+		assert.Equal(t, l.id, payload.ListenerID)
+		if payload.Status.State == state {
+			return payload.Status
+		}
+	}
+}
+
+// ExpectNoStatusChanges verifies that the listener does not receive any notifications within a
+// short interval.
+func (l *DataSourceStatusListener) ExpectNoStatusChanges(t *ldtest.T) {
+	maybePayload := helpers.TryReceive(l.callbackService.CallChannel, listenerWaitForNoCallTimeout)
+	assert.False(t, maybePayload.IsDefined(), "Expected no data source status notifications, got %s",
+		maybePayload.Value().Status.State)
+}
+
+func keysOf(m map[string]bool) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
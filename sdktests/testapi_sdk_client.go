@@ -10,6 +10,7 @@ import (
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
 	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
 	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
 
 	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
@@ -66,6 +67,32 @@ func WithClientSideInitialContext(context ldcontext.Context) SDKConfigurer {
 	})
 }
 
+// WithClientSideBootstrap is used with StartSDKClient to provide initial flag data to a client-side
+// SDK, in the format that a server-side SDK's allFlagsState method would produce. This will only work
+// if the test service has the "bootstrap" capability.
+func WithClientSideBootstrap(data mockld.ClientSDKData) SDKConfigurer {
+	return helpers.ConfigOptionFunc[servicedef.SDKConfigParams](func(configOut *servicedef.SDKConfigParams) error {
+		cs := configOut.ClientSide.Value()
+		cs.Bootstrap = data.BootstrapJSON()
+		configOut.ClientSide = o.Some(cs)
+		return nil
+	})
+}
+
+// WithClientSideFlagCache is used with StartSDKClient to make a client-side SDK cache flag values in
+// the persistent storage identified by namespace, keeping values for at most maxCachedContexts contexts
+// (or the SDK's default limit, if it is not defined). This will only work if the test service has the
+// "client-flag-cache" capability.
+func WithClientSideFlagCache(namespace string, maxCachedContexts o.Maybe[int]) SDKConfigurer {
+	return helpers.ConfigOptionFunc[servicedef.SDKConfigParams](func(configOut *servicedef.SDKConfigParams) error {
+		cs := configOut.ClientSide.Value()
+		cs.StorageNamespace = o.Some(namespace)
+		cs.MaxCachedContexts = maxCachedContexts
+		configOut.ClientSide = o.Some(cs)
+		return nil
+	})
+}
+
 // WithEventsConfig is used with StartSDKClient to specify a non-default events configuration.
 func WithEventsConfig(eventsConfig servicedef.SDKConfigEventParams) SDKConfigurer {
 	return helpers.ConfigOptionFunc[servicedef.SDKConfigParams](func(configOut *servicedef.SDKConfigParams) error {
@@ -190,18 +217,23 @@ func TryNewSDKClient(t *ldtest.T, configurers ...SDKConfigurer) (*SDKClient, err
 }
 
 func validateSDKConfig(config servicedef.SDKConfigParams) error {
-	if !config.Streaming.IsDefined() && !config.Polling.IsDefined() &&
+	if config.DataSystem.IsDefined() {
+		// The top-level streaming and polling properties are ignored if the FDv2 data system is configured
+		if len(config.DataSystem.Value().Initializers) == 0 && !config.DataSystem.Value().Synchronizers.IsDefined() {
+			return errors.New("data system was configured with neither initializers nor synchronizers")
+		}
+	} else if !config.Streaming.IsDefined() && !config.Polling.IsDefined() && !config.FileDataSource.IsDefined() &&
 		!config.PersistentDataStore.IsDefined() && config.ServiceEndpoints.Value().Streaming == "" {
 		// Note that the default is streaming, so we don't necessarily need to set config.Streaming if there are
 		// no other customized options and if we used serviceEndpoints.streaming to set the stream URI
 		return errors.New(
 			"neither streaming nor polling was enabled-- did you forget to include the SDKDataSource as a parameter?")
 	}
-	if config.Streaming.IsDefined() && config.Streaming.Value().BaseURI == "" &&
+	if !config.DataSystem.IsDefined() && config.Streaming.IsDefined() && config.Streaming.Value().BaseURI == "" &&
 		(!config.ServiceEndpoints.IsDefined() || config.ServiceEndpoints.Value().Streaming == "") {
 		return errors.New("streaming was enabled but base URI was not set")
 	}
-	if config.Polling.IsDefined() && config.Polling.Value().BaseURI == "" &&
+	if !config.DataSystem.IsDefined() && config.Polling.IsDefined() && config.Polling.Value().BaseURI == "" &&
 		(!config.ServiceEndpoints.IsDefined() || config.ServiceEndpoints.Value().Polling == "") {
 		return errors.New("polling was enabled but base URI was not set")
 	}
@@ -347,6 +379,32 @@ func (c *SDKClient) FlushEvents(t *ldtest.T) {
 	require.NoError(t, c.sdkClientEntity.SendCommand(servicedef.CommandFlushEvents, t.DebugLogger(), nil))
 }
 
+// SetOffline tells a client-side SDK to go offline, or to return online. The test harness will only call
+// this method if the test service has the "connection-mode" capability.
+func (c *SDKClient) SetOffline(t *ldtest.T, offline bool) {
+	require.NoError(t, c.sdkClientEntity.SendCommandWithParams(
+		servicedef.CommandParams{
+			Command:    servicedef.CommandSetOffline,
+			SetOffline: o.Some(servicedef.SetOfflineParams{Offline: offline}),
+		},
+		t.DebugLogger(),
+		nil,
+	))
+}
+
+// SetConnectionMode tells a client-side SDK to switch to a different connection mode. The test harness
+// will only call this method if the test service has the "connection-mode" capability.
+func (c *SDKClient) SetConnectionMode(t *ldtest.T, mode servicedef.ConnectionMode) {
+	require.NoError(t, c.sdkClientEntity.SendCommandWithParams(
+		servicedef.CommandParams{
+			Command:           servicedef.CommandSetConnectionMode,
+			SetConnectionMode: o.Some(servicedef.SetConnectionModeParams{Mode: mode}),
+		},
+		t.DebugLogger(),
+		nil,
+	))
+}
+
 // GetBigSegmentStoreStatus queries the big segment store status from the SDK client. The test
 // harness will only call this method if the test service has the "big-segments" capability.
 func (c *SDKClient) GetBigSegmentStoreStatus(t *ldtest.T) servicedef.BigSegmentStoreStatusResponse {
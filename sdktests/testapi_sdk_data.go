@@ -3,6 +3,7 @@ package sdktests
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/helpers"
@@ -15,14 +16,20 @@ import (
 // SDKDataSource is a test fixture that provides a callback endpoint for SDK clients to connect to,
 // simulating the LaunchDarkly streaming or polling service.
 type SDKDataSource struct {
-	streamingService *mockld.StreamingService
-	pollingService   *mockld.PollingService
-	endpoint         *harness.MockEndpoint
+	streamingService     *mockld.StreamingService
+	pollingService       *mockld.PollingService
+	fdv2StreamingService *mockld.FDv2StreamingService
+	fdv2PollingService   *mockld.FDv2PollingService
+	endpoint             *harness.MockEndpoint
 }
 
 type sdkDataSourceConfig struct {
-	polling       o.Maybe[bool] // true, false, or "undefined, use the default"
-	environmentID o.Maybe[string]
+	polling              o.Maybe[bool] // true, false, or "undefined, use the default"
+	environmentID        o.Maybe[string]
+	fdv2                 bool
+	streamingWithPolling bool
+	eventIDs             bool
+	partialReplay        bool
 }
 
 // SDKDataSourceOption is the interface for options to NewSDKDataSource.
@@ -44,6 +51,17 @@ func DataSourceOptionStreaming() SDKDataSourceOption {
 	})
 }
 
+// DataSourceOptionStreamingWithPolling makes an SDKDataSource simulate both the streaming service and
+// the polling service on the same endpoint, sharing the same data. This is only valid for client-side
+// SDKs. It allows tests to send a "ping" event on the stream, which should cause the SDK to request the
+// latest data from the polling service.
+func DataSourceOptionStreamingWithPolling() SDKDataSourceOption {
+	return helpers.ConfigOptionFunc[sdkDataSourceConfig](func(c *sdkDataSourceConfig) error {
+		c.streamingWithPolling = true
+		return nil
+	})
+}
+
 // DataSourceOptionEnvironmentID makes an SDKDataSource report an environment ID in the
 // X-LD-EnvID response header, as LaunchDarkly does.
 func DataSourceOptionEnvironmentID(environmentID string) SDKDataSourceOption {
@@ -53,6 +71,37 @@ func DataSourceOptionEnvironmentID(environmentID string) SDKDataSourceOption {
 	})
 }
 
+// DataSourceOptionFDv2 makes an SDKDataSource simulate the FDv2 version of the streaming or polling
+// service, and configure the SDK's "dataSystem" property rather than its FDv1 streaming or polling
+// properties. This is only valid for server-side SDKs.
+func DataSourceOptionFDv2() SDKDataSourceOption {
+	return helpers.ConfigOptionFunc[sdkDataSourceConfig](func(c *sdkDataSourceConfig) error {
+		c.fdv2 = true
+		return nil
+	})
+}
+
+// DataSourceOptionEventIDs makes an SDKDataSource give every event on the stream an ID, so that the
+// SDK should send the ID of the last event it received in the Last-Event-ID header when it
+// reconnects. This only affects FDv1 streaming data sources.
+func DataSourceOptionEventIDs() SDKDataSourceOption {
+	return helpers.ConfigOptionFunc[sdkDataSourceConfig](func(c *sdkDataSourceConfig) error {
+		c.eventIDs = true
+		return nil
+	})
+}
+
+// DataSourceOptionPartialReplay is like DataSourceOptionEventIDs, but also makes the SDKDataSource
+// respond to a reconnection with a known Last-Event-ID by sending only the events that were sent
+// after that one, rather than the full data set. This only affects FDv1 streaming data sources.
+func DataSourceOptionPartialReplay() SDKDataSourceOption {
+	return helpers.ConfigOptionFunc[sdkDataSourceConfig](func(c *sdkDataSourceConfig) error {
+		c.eventIDs = true
+		c.partialReplay = true
+		return nil
+	})
+}
+
 // NewSDKDataSource creates a new SDKDataSource with the specified initial data set.
 //
 // It can simulate either the streaming service or the polling service. If you don't explicitly specify
@@ -72,9 +121,12 @@ func DataSourceOptionEnvironmentID(environmentID string) SDKDataSourceOption {
 func NewSDKDataSource(t *ldtest.T, data mockld.SDKData, options ...SDKDataSourceOption) *SDKDataSource {
 	d := NewSDKDataSourceWithoutEndpoint(t, data, options...)
 
-	isPolling := d.pollingService != nil
-	handler := helpers.IfElse[http.Handler](isPolling, d.pollingService, d.streamingService)
+	isPolling := d.pollingService != nil || d.fdv2PollingService != nil
+	handler := d.Handler()
 	description := helpers.IfElse(isPolling, "polling service", "streaming service")
+	if d.streamingService != nil && d.pollingService != nil {
+		description = "streaming and polling service"
+	}
 
 	var config sdkDataSourceConfig
 	_ = helpers.ApplyOptions(&config, options...)
@@ -104,18 +156,50 @@ func NewSDKDataSourceWithoutEndpoint(t *ldtest.T, data mockld.SDKData, options .
 
 	defaultIsPolling := sdkKind == mockld.JSClientSDK || sdkKind == mockld.PHPSDK
 	d := &SDKDataSource{}
-	if config.polling.Value() || (!config.polling.IsDefined() && defaultIsPolling) {
+	if config.fdv2 {
+		payload := mockld.NewFDv2Payload(fdv2PayloadID, data)
+		if config.polling.Value() {
+			d.fdv2PollingService = mockld.NewFDv2PollingService(payload, t.DebugLogger())
+		} else {
+			d.fdv2StreamingService = mockld.NewFDv2StreamingService(payload, t.DebugLogger())
+		}
+	} else if config.streamingWithPolling {
+		d.streamingService = mockld.NewStreamingService(data, sdkKind, t.DebugLogger())
+		d.pollingService = mockld.NewPollingService(data, sdkKind, t.DebugLogger()).
+			WithGzipCompression(t.Capabilities().Has(servicedef.CapabilityPollingGzip))
+	} else if config.polling.Value() || (!config.polling.IsDefined() && defaultIsPolling) {
 		d.pollingService = mockld.NewPollingService(data, sdkKind, t.DebugLogger()).
 			WithGzipCompression(t.Capabilities().Has(servicedef.CapabilityPollingGzip))
 	} else {
 		d.streamingService = mockld.NewStreamingService(data, sdkKind, t.DebugLogger())
 	}
+	if d.streamingService != nil {
+		d.streamingService.WithEventIDs(config.eventIDs).WithPartialReplay(config.partialReplay)
+	}
 
 	t.Debug("setting SDK data to: %s", string(data.Serialize()))
 
 	return d
 }
 
+// streamingWithPollingHandler sends polling requests to the polling service and all other requests to
+// the streaming service.
+func streamingWithPollingHandler(streaming, polling http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isClientSidePollingPath(r.URL.Path) {
+			polling.ServeHTTP(w, r)
+		} else {
+			streaming.ServeHTTP(w, r)
+		}
+	})
+}
+
+// isClientSidePollingPath returns true if the URL path is for the client-side polling service. Those paths
+// all start with "/sdk/" or "/msdk/", which none of the client-side streaming paths do.
+func isClientSidePollingPath(path string) bool {
+	return strings.HasPrefix(path, "/sdk/") || strings.HasPrefix(path, "/msdk/")
+}
+
 func withEnvironmentIDHeader(handler http.Handler, environmentID string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(environmentIDHeader, environmentID)
@@ -134,8 +218,31 @@ func (d *SDKDataSource) StreamingService() *mockld.StreamingService { return d.s
 // streaming data source.
 func (d *SDKDataSource) PollingService() *mockld.PollingService { return d.pollingService }
 
+// FDv2StreamingService returns the low-level object that manages the FDv2 stream data, or nil if
+// this is not an FDv2 streaming data source.
+func (d *SDKDataSource) FDv2StreamingService() *mockld.FDv2StreamingService {
+	return d.fdv2StreamingService
+}
+
+// FDv2PollingService returns the low-level object that manages the FDv2 polling data, or nil if
+// this is not an FDv2 polling data source.
+func (d *SDKDataSource) FDv2PollingService() *mockld.FDv2PollingService { return d.fdv2PollingService }
+
+// FDv2Payload returns the data state of an FDv2 data source, or nil if this is not an FDv2 data source.
+func (d *SDKDataSource) FDv2Payload() *mockld.FDv2Payload {
+	switch {
+	case d.fdv2StreamingService != nil:
+		return d.fdv2StreamingService.Payload()
+	case d.fdv2PollingService != nil:
+		return d.fdv2PollingService.Payload()
+	default:
+		return nil
+	}
+}
+
 // SetInitialData configures whichever kind of data source this is (streaming or polling) to use
-// the specified data set the next time it receives an SDK connection.
+// the specified data set the next time it receives an SDK connection. If the data source simulates
+// both streaming and polling, it also determines what the next poll request will receive.
 func (d *SDKDataSource) SetInitialData(data mockld.SDKData) {
 	if d.streamingService != nil {
 		d.streamingService.SetInitialData(data)
@@ -143,11 +250,26 @@ func (d *SDKDataSource) SetInitialData(data mockld.SDKData) {
 	if d.pollingService != nil {
 		d.pollingService.SetData(data)
 	}
+	if payload := d.FDv2Payload(); payload != nil {
+		payload.SetData(data)
+	}
 }
 
-// Handler returns the HTTP handler for the service. Since StreamingService implements http.Handler
-// already, this is the same as Service() but makes the purpose clearer.
-func (d *SDKDataSource) Handler() http.Handler { return d.streamingService }
+// Handler returns the HTTP handler for whichever kind of service this is.
+func (d *SDKDataSource) Handler() http.Handler {
+	switch {
+	case d.streamingService != nil && d.pollingService != nil:
+		return streamingWithPollingHandler(d.streamingService, d.pollingService)
+	case d.pollingService != nil:
+		return d.pollingService
+	case d.fdv2StreamingService != nil:
+		return d.fdv2StreamingService
+	case d.fdv2PollingService != nil:
+		return d.fdv2PollingService
+	default:
+		return d.streamingService
+	}
+}
 
 // Configure updates the SDK client configuration for NewSDKClient, causing the SDK
 // to connect to the appropriate base URI for the data source test fixture. This only works if
@@ -158,6 +280,10 @@ func (d *SDKDataSource) Configure(config *servicedef.SDKConfigParams) error {
 	if d.endpoint == nil {
 		return errors.New("tried to use an SDKDataSource without its own endpoint as a parameter to NewSDKClient")
 	}
+	if d.fdv2StreamingService != nil || d.fdv2PollingService != nil {
+		d.configureFDv2(config)
+		return nil
+	}
 	if d.streamingService == nil && d.pollingService == nil {
 		return errors.New("tried to use an SDKDataSource that has neither streaming nor polling configured")
 	}
@@ -173,3 +299,29 @@ func (d *SDKDataSource) Configure(config *servicedef.SDKConfigParams) error {
 	}
 	return nil
 }
+
+// configureFDv2 adds this data source as a synchronizer in the FDv2 data system configuration: the
+// primary one if there isn't one already, otherwise the secondary one. Any options that were set in
+// the top-level streaming or polling configuration, such as the poll interval, are copied to it.
+func (d *SDKDataSource) configureFDv2(config *servicedef.SDKConfigParams) {
+	var synchronizer servicedef.SDKConfigSynchronizerParams
+	if d.fdv2StreamingService != nil {
+		streaming := config.Streaming.Value()
+		streaming.BaseURI = d.endpoint.BaseURL()
+		synchronizer.Streaming = o.Some(streaming)
+	} else {
+		polling := config.Polling.Value()
+		polling.BaseURI = d.endpoint.BaseURL()
+		synchronizer.Polling = o.Some(polling)
+	}
+
+	dataSystem := config.DataSystem.Value()
+	synchronizers := dataSystem.Synchronizers.Value()
+	if synchronizers.Primary.IsDefined() {
+		synchronizers.Secondary = o.Some(synchronizer)
+	} else {
+		synchronizers.Primary = o.Some(synchronizer)
+	}
+	dataSystem.Synchronizers = o.Some(synchronizers)
+	config.DataSystem = o.Some(dataSystem)
+}
@@ -102,3 +102,25 @@ func (e *SDKEventSink) ExpectNoAnalyticsEvents(t require.TestingT, timeout time.
 		require.Fail(t, "received events when none were expected", "events: %s", events.JSONString())
 	}
 }
+
+// ExpectDiagnosticEvent waits for a diagnostic event to be posted to the endpoint and returns it. This
+// can be either the "diagnostic-init" event or a periodic "diagnostic" event; analytics event payloads
+// are not affected.
+//
+// If no diagnostic event arrives before the timeout, the test immediately fails and terminates.
+func (e *SDKEventSink) ExpectDiagnosticEvent(t require.TestingT, timeout time.Duration) mockld.Event {
+	event, ok := e.eventsService.AwaitDiagnosticEvent(timeout)
+	if !ok {
+		require.Fail(t, "timed out waiting for diagnostic event")
+	}
+	return event
+}
+
+// ExpectNoDiagnosticEvents waits for the specified timeout and fails if any diagnostic events are
+// posted before then.
+func (e *SDKEventSink) ExpectNoDiagnosticEvents(t require.TestingT, timeout time.Duration) {
+	event, ok := e.eventsService.AwaitDiagnosticEvent(timeout)
+	if ok {
+		require.Fail(t, "received diagnostic event when none was expected", "event: %s", event.JSONString())
+	}
+}
@@ -0,0 +1,89 @@
+package sdktests
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
+	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
+	"github.com/launchdarkly/sdk-test-harness/v2/mockld"
+	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	"github.com/launchdarkly/go-test-helpers/v2/jsonhelpers"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// SDKFileDataSource is a test fixture that provides data files for an SDK's file data source. The files
+// are written to a temporary directory on the host that is running the test harness, so the test service
+// must be able to see the same filesystem.
+type SDKFileDataSource struct {
+	dir        string
+	paths      []string
+	autoUpdate bool
+}
+
+// NewSDKFileDataSource creates a temporary directory for data files. If autoUpdate is true, the SDK
+// will be configured to reload the data whenever a file changes.
+//
+// The object's lifecycle is tied to the test scope that created it; the directory and all of its files
+// will be deleted when this test scope exits.
+func NewSDKFileDataSource(t *ldtest.T, autoUpdate bool) *SDKFileDataSource {
+	dir, err := os.MkdirTemp("", "sdk-test-harness-files-")
+	require.NoError(t, err)
+	t.Defer(func() { _ = os.RemoveAll(dir) })
+	return &SDKFileDataSource{dir: dir, autoUpdate: autoUpdate}
+}
+
+// WriteFile creates or replaces the data file with the specified name. Every file that has been written
+// before the SDK client is created will be included in its configuration, in the order that they were
+// first written.
+func (f *SDKFileDataSource) WriteFile(t *ldtest.T, name string, content []byte) {
+	path := filepath.Join(f.dir, name)
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	t.Debug("wrote data file %s: %s", path, string(content))
+	for _, p := range f.paths {
+		if p == path {
+			return
+		}
+	}
+	f.paths = append(f.paths, path)
+}
+
+// Configure updates the SDK client configuration for NewSDKClient, causing the SDK to read its data
+// from the files in this fixture.
+func (f *SDKFileDataSource) Configure(config *servicedef.SDKConfigParams) error {
+	if len(f.paths) == 0 {
+		return errors.New("SDKFileDataSource must have at least one file before the SDK client is created")
+	}
+	config.FileDataSource = o.Some(servicedef.SDKConfigFileDataSourceParams{
+		Paths:      append([]string(nil), f.paths...),
+		AutoUpdate: f.autoUpdate,
+	})
+	return nil
+}
+
+// fileDataJSON returns the content of a data file, in JSON format, that contains the full configuration
+// of every flag and segment in the data.
+func fileDataJSON(data mockld.ServerSDKData) []byte {
+	return data.Serialize()
+}
+
+// fileDataYAML is the same as fileDataJSON, but in YAML format.
+func fileDataYAML(t *ldtest.T, data mockld.ServerSDKData) []byte {
+	var parsed any
+	require.NoError(t, json.Unmarshal(data.Serialize(), &parsed))
+	out, err := yaml.Marshal(parsed)
+	require.NoError(t, err)
+	return out
+}
+
+// fileDataFlagValues returns the content of a data file, in JSON format, that uses the simplified
+// "flagValues" syntax where each flag is represented only by the value it always returns.
+func fileDataFlagValues(values map[string]ldvalue.Value) []byte {
+	return jsonhelpers.ToJSON(map[string]any{"flagValues": values})
+}
@@ -3,8 +3,11 @@ package sdktests
 import (
 	"errors"
 
+	"github.com/launchdarkly/sdk-test-harness/v2/framework/ldtest"
 	o "github.com/launchdarkly/sdk-test-harness/v2/framework/opt"
 	"github.com/launchdarkly/sdk-test-harness/v2/servicedef"
+
+	"github.com/stretchr/testify/require"
 )
 
 type Persistence struct {
@@ -36,3 +39,12 @@ func (p Persistence) Configure(target *servicedef.SDKConfigParams) error {
 
 	return nil
 }
+
+// GetDataStoreStatus queries the persistent data store status from the SDK client. The test harness will
+// only call this method if the test service has the "data-store-status" capability.
+func (c *SDKClient) GetDataStoreStatus(t *ldtest.T) servicedef.DataStoreStatus {
+	var resp servicedef.DataStoreStatus
+	require.NoError(t, c.sdkClientEntity.SendCommand(servicedef.CommandGetDataStoreStatus,
+		t.DebugLogger(), &resp))
+	return resp
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/launchdarkly/sdk-test-harness/v2/framework"
 	"github.com/launchdarkly/sdk-test-harness/v2/framework/harness"
@@ -17,6 +18,9 @@ func RunSDKTestSuite(
 	filter ldtest.Filter,
 	testLogger ldtest.TestLogger,
 	enableLongRunningTests bool,
+	maxParallel int,
+	defaultTimeout time.Duration,
+	retryFailures int,
 ) ldtest.Results {
 	capabilities := harness.TestServiceInfo().Capabilities
 	var importantCapabilities framework.Capabilities
@@ -53,6 +57,11 @@ func RunSDKTestSuite(
 		}
 	}
 
+	if maxParallel > 1 && capabilities.Has(servicedef.CapabilitySingleton) {
+		fmt.Println("Running tests sequentially, because the test service only allows one SDK client at a time")
+		maxParallel = 1
+	}
+
 	fmt.Println()
 	if sdf, ok := filter.(ldtest.SelfDescribingFilter); ok {
 		sdf.Describe(os.Stdout, capabilities, importantCapabilities)
@@ -63,6 +72,11 @@ func RunSDKTestSuite(
 		Capabilities:           harness.TestServiceInfo().Capabilities,
 		TestLogger:             testLogger,
 		EnableLongRunningTests: enableLongRunningTests,
+		MaxParallel:            maxParallel,
+		CheckService:           harness.CheckService,
+		DefaultTimeout:         defaultTimeout,
+		RetryFailures:          retryFailures,
+		DryRun:                 harness.IsDryRun(),
 		Context: SDKTestContext{
 			harness: harness,
 			sdkKind: sdkKind,
@@ -70,6 +84,10 @@ func RunSDKTestSuite(
 	}
 
 	return ldtest.Run(config, func(t *ldtest.T) {
+		// If the test harness started the test service, its output goes to whichever tests are running.
+		harness.SetServiceOutputLogger(t.SubtestLogger())
+		defer harness.SetServiceOutputLogger(nil)
+
 		switch sdkKind {
 		case mockld.ServerSideSDK:
 			doAllServerSideTests(t)
@@ -86,6 +104,9 @@ func doAllServerSideTests(t *ldtest.T) {
 	t.Run("events", doServerSideEventTests)
 	t.Run("streaming", doServerSideStreamTests)
 	t.Run("polling", doServerSidePollTests)
+	t.Run("fdv2", doServerSideFDv2Tests)
+	t.Run("data source status", doServerSideDataSourceStatusTests)
+	t.Run("flag change listeners", doServerSideFlagChangeListenerTests)
 	t.Run("big segments", doServerSideBigSegmentsTests)
 	t.Run("service endpoints", doServerSideServiceEndpointsTests)
 	t.Run("tags", doServerSideTagsTests)
@@ -98,6 +119,7 @@ func doAllServerSideTests(t *ldtest.T) {
 	t.Run("hooks", doCommonHooksTests)
 	t.Run("wrapper", doServerSideWrapperTests)
 	t.Run("persistent data store", doServerSidePersistentTests)
+	t.Run("file data source", doServerSideFileDataSourceTests)
 }
 
 func doAllClientSideTests(t *ldtest.T) {
@@ -105,6 +127,9 @@ func doAllClientSideTests(t *ldtest.T) {
 	t.Run("events", doClientSideEventTests)
 	t.Run("streaming", doClientSideStreamTests)
 	t.Run("polling", doClientSidePollTests)
+	t.Run("connection mode", doClientSideConnectionModeTests)
+	t.Run("bootstrap", doClientSideBootstrapTests)
+	t.Run("flag cache", doClientSideFlagCacheTests)
 	t.Run("tags", doClientSideTagsTests)
 	t.Run("instance id", func(t *ldtest.T) {
 		NewCommonInstanceIDTests(t, "doClientSideInstanceIdTests").Run(t)
@@ -8,6 +8,7 @@ import (
 	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
 	"github.com/launchdarkly/go-sdk-common/v3/ldmigration"
 	"github.com/launchdarkly/go-sdk-common/v3/ldreason"
+	"github.com/launchdarkly/go-sdk-common/v3/ldtime"
 	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
 )
 
@@ -25,6 +26,16 @@ const (
 	CommandSecureModeHash           = "secureModeHash"
 	CommandMigrationVariation       = "migrationVariation"
 	CommandMigrationOperation       = "migrationOperation"
+
+	CommandGetDataSourceStatus              = "getDataSourceStatus"
+	CommandRegisterFlagChangeListener       = "registerFlagChangeListener"
+	CommandRegisterDataSourceStatusListener = "registerDataSourceStatusListener"
+	CommandUnregisterListener               = "unregisterListener"
+
+	CommandSetOffline        = "setOffline"
+	CommandSetConnectionMode = "setConnectionMode"
+
+	CommandGetDataStoreStatus = "getDataStoreStatus"
 )
 
 type ValueType string
@@ -49,6 +60,10 @@ type CommandParams struct {
 	SecureModeHash     o.Maybe[SecureModeHashParams]        `json:"secureModeHash,omitempty"`
 	MigrationVariation o.Maybe[MigrationVariationParams]    `json:"migrationVariation,omitempty"`
 	MigrationOperation o.Maybe[MigrationOperationParams]    `json:"migrationOperation,omitempty"`
+	RegisterListener   o.Maybe[RegisterListenerParams]      `json:"registerListener,omitempty"`
+	UnregisterListener o.Maybe[UnregisterListenerParams]    `json:"unregisterListener,omitempty"`
+	SetOffline         o.Maybe[SetOfflineParams]            `json:"setOffline,omitempty"`
+	SetConnectionMode  o.Maybe[SetConnectionModeParams]     `json:"setConnectionMode,omitempty"`
 }
 
 type EvaluateFlagParams struct {
@@ -224,3 +239,95 @@ type HookExecutionPayload struct {
 	// assert cross-hook ordering. Not part of the SDK contract.
 	Sequence int64 `json:"-"`
 }
+
+type DataSourceState string
+
+const (
+	DataSourceStateInitializing DataSourceState = "INITIALIZING"
+	DataSourceStateValid        DataSourceState = "VALID"
+	DataSourceStateInterrupted  DataSourceState = "INTERRUPTED"
+	DataSourceStateOff          DataSourceState = "OFF"
+)
+
+type DataSourceErrorKind string
+
+const (
+	DataSourceErrorKindUnknown       DataSourceErrorKind = "UNKNOWN"
+	DataSourceErrorKindNetworkError  DataSourceErrorKind = "NETWORK_ERROR"
+	DataSourceErrorKindErrorResponse DataSourceErrorKind = "ERROR_RESPONSE"
+	DataSourceErrorKindInvalidData   DataSourceErrorKind = "INVALID_DATA"
+	DataSourceErrorKindStoreError    DataSourceErrorKind = "STORE_ERROR"
+)
+
+type DataSourceErrorInfo struct {
+	Kind       DataSourceErrorKind        `json:"kind"`
+	StatusCode o.Maybe[int]               `json:"statusCode,omitempty"`
+	Message    o.Maybe[string]            `json:"message,omitempty"`
+	Time       ldtime.UnixMillisecondTime `json:"time"`
+}
+
+// DataSourceStatus is the response to the getDataSourceStatus command, and is also the status
+// that is reported in a DataSourceStatusCallbackPayload.
+type DataSourceStatus struct {
+	State      DataSourceState              `json:"state"`
+	StateSince ldtime.UnixMillisecondTime   `json:"stateSince"`
+	LastError  o.Maybe[DataSourceErrorInfo] `json:"lastError,omitempty"`
+}
+
+// RegisterListenerParams are the parameters of the registerFlagChangeListener and
+// registerDataSourceStatusListener commands. The test service should post a callback payload to
+// CallbackURI each time the listener is notified, until the listener is unregistered.
+type RegisterListenerParams struct {
+	ListenerID  string `json:"listenerId"`
+	CallbackURI string `json:"callbackUri"`
+}
+
+type UnregisterListenerParams struct {
+	ListenerID string `json:"listenerId"`
+}
+
+// FlagChangeCallbackPayload is posted by the test service when a flag change listener is notified.
+type FlagChangeCallbackPayload struct {
+	ListenerID string `json:"listenerId"`
+	FlagKey    string `json:"flagKey"`
+}
+
+// DataSourceStatusCallbackPayload is posted by the test service when a data source status listener
+// is notified.
+type DataSourceStatusCallbackPayload struct {
+	ListenerID string           `json:"listenerId"`
+	Status     DataSourceStatus `json:"status"`
+}
+
+// SetOfflineParams are the parameters of the setOffline command.
+type SetOfflineParams struct {
+	Offline bool `json:"offline"`
+}
+
+// ConnectionMode is a value for the mode property of the setConnectionMode command.
+type ConnectionMode string
+
+const (
+	// ConnectionModeStreaming means the SDK should get flag updates from the streaming service.
+	ConnectionModeStreaming ConnectionMode = "streaming"
+	// ConnectionModePolling means the SDK should get flag updates from the polling service, at the
+	// configured polling interval.
+	ConnectionModePolling ConnectionMode = "polling"
+	// ConnectionModeBackground means the SDK should behave as it does when the application is in the
+	// background.
+	ConnectionModeBackground ConnectionMode = "background"
+	// ConnectionModeForeground means the SDK should behave as it does when the application returns to
+	// the foreground, using whatever mode it was configured with.
+	ConnectionModeForeground ConnectionMode = "foreground"
+)
+
+// SetConnectionModeParams are the parameters of the setConnectionMode command.
+type SetConnectionModeParams struct {
+	Mode ConnectionMode `json:"mode"`
+}
+
+// DataStoreStatus is the response to the getDataStoreStatus command.
+type DataStoreStatus struct {
+	Available     bool `json:"available"`
+	RefreshNeeded bool `json:"refreshNeeded"`
+}
@@ -27,6 +27,8 @@ type SDKConfigParams struct {
 	Hooks               o.Maybe[SDKConfigHooksParams]               `json:"hooks,omitempty"`
 	Wrapper             o.Maybe[SDKConfigWrapper]                   `json:"wrapper,omitempty"`
 	PersistentDataStore o.Maybe[SDKConfigPersistentDataStoreParams] `json:"persistentDataStore,omitempty"`
+	DataSystem          o.Maybe[SDKConfigDataSystemParams]          `json:"dataSystem,omitempty"`
+	FileDataSource      o.Maybe[SDKConfigFileDataSourceParams]      `json:"fileDataSource,omitempty"`
 }
 
 type SDKConfigTLSParams struct {
@@ -34,6 +36,11 @@ type SDKConfigTLSParams struct {
 	CustomCAFile   string `json:"customCAFile,omitempty"`
 }
 
+type SDKConfigFileDataSourceParams struct {
+	Paths      []string `json:"paths"`
+	AutoUpdate bool     `json:"autoUpdate,omitempty"`
+}
+
 type SDKConfigProxyParams struct {
 	HTTPProxy o.Maybe[string] `json:"httpProxy,omitempty"`
 }
@@ -47,6 +54,7 @@ type SDKConfigServiceEndpointsParams struct {
 type SDKConfigStreamingParams struct {
 	BaseURI             string                              `json:"baseUri,omitempty"`
 	InitialRetryDelayMS o.Maybe[ldtime.UnixMillisecondTime] `json:"initialRetryDelayMs,omitempty"`
+	ReadTimeoutMS       o.Maybe[ldtime.UnixMillisecondTime] `json:"readTimeoutMs,omitempty"`
 	Filter              o.Maybe[string]                     `json:"filter,omitempty"`
 }
 
@@ -56,15 +64,40 @@ type SDKConfigPollingParams struct {
 	Filter         o.Maybe[string]                     `json:"filter,omitempty"`
 }
 
+// SDKConfigDataSystemParams configures the FDv2 data system. If this is present, the test service
+// should ignore the top-level Streaming and Polling properties.
+type SDKConfigDataSystemParams struct {
+	Initializers  []SDKConfigDataInitializerParams      `json:"initializers,omitempty"`
+	Synchronizers o.Maybe[SDKConfigSynchronizersParams] `json:"synchronizers,omitempty"`
+	PayloadFilter o.Maybe[string]                       `json:"payloadFilter,omitempty"`
+}
+
+type SDKConfigDataInitializerParams struct {
+	Polling o.Maybe[SDKConfigPollingParams] `json:"polling,omitempty"`
+}
+
+type SDKConfigSynchronizersParams struct {
+	Primary   o.Maybe[SDKConfigSynchronizerParams] `json:"primary,omitempty"`
+	Secondary o.Maybe[SDKConfigSynchronizerParams] `json:"secondary,omitempty"`
+}
+
+// SDKConfigSynchronizerParams describes a single FDv2 synchronizer. Exactly one of its properties
+// will be set.
+type SDKConfigSynchronizerParams struct {
+	Streaming o.Maybe[SDKConfigStreamingParams] `json:"streaming,omitempty"`
+	Polling   o.Maybe[SDKConfigPollingParams]   `json:"polling,omitempty"`
+}
+
 type SDKConfigEventParams struct {
-	BaseURI                 string                              `json:"baseUri,omitempty"`
-	Capacity                o.Maybe[int]                        `json:"capacity,omitempty"`
-	EnableDiagnostics       bool                                `json:"enableDiagnostics"`
-	AllAttributesPrivate    bool                                `json:"allAttributesPrivate,omitempty"`
-	GlobalPrivateAttributes []string                            `json:"globalPrivateAttributes,omitempty"`
-	FlushIntervalMS         o.Maybe[ldtime.UnixMillisecondTime] `json:"flushIntervalMs,omitempty"`
-	OmitAnonymousContexts   bool                                `json:"omitAnonymousContexts,omitempty"`
-	EnableGzip              o.Maybe[bool]                       `json:"enableGzip,omitempty"`
+	BaseURI                       string                              `json:"baseUri,omitempty"`
+	Capacity                      o.Maybe[int]                        `json:"capacity,omitempty"`
+	EnableDiagnostics             bool                                `json:"enableDiagnostics"`
+	AllAttributesPrivate          bool                                `json:"allAttributesPrivate,omitempty"`
+	GlobalPrivateAttributes       []string                            `json:"globalPrivateAttributes,omitempty"`
+	FlushIntervalMS               o.Maybe[ldtime.UnixMillisecondTime] `json:"flushIntervalMs,omitempty"`
+	OmitAnonymousContexts         bool                                `json:"omitAnonymousContexts,omitempty"`
+	EnableGzip                    o.Maybe[bool]                       `json:"enableGzip,omitempty"`
+	DiagnosticRecordingIntervalMS o.Maybe[ldtime.UnixMillisecondTime] `json:"diagnosticRecordingIntervalMs,omitempty"`
 }
 
 type SDKConfigBigSegmentsParams struct {
@@ -87,6 +120,9 @@ type SDKConfigClientSideParams struct {
 	UseReport                    o.Maybe[bool]              `json:"useReport,omitempty"`
 	IncludeEnvironmentAttributes o.Maybe[bool]              `json:"includeEnvironmentAttributes,omitempty"`
 	Hash                         o.Maybe[string]            `json:"hash,omitempty"`
+	Bootstrap                    json.RawMessage            `json:"bootstrap,omitempty"`
+	StorageNamespace             o.Maybe[string]            `json:"storageNamespace,omitempty"`
+	MaxCachedContexts            o.Maybe[int]               `json:"maxCachedContexts,omitempty"`
 }
 
 type SDKConfigEvaluationHookData map[string]ldvalue.Value
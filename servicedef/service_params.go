@@ -48,6 +48,7 @@ const (
 	CapabilityPersistentDataStoreConsul   = "persistent-data-store-consul"
 	CapabilityPersistentDataStoreDynamoDB = "persistent-data-store-dynamodb"
 	CapabilityClientPerContextSummaries   = "client-per-context-summaries"
+	CapabilityDiagnosticEvents            = "diagnostic-events"
 
 	// CapabilityTLSVerifyPeer means the SDK is capable of establishing a TLS session and verifying
 	// its peer. This is generally a standard capability of all SDKs.
@@ -103,8 +104,126 @@ const (
 	// and only run when `-enable-long-running-tests` is set. Legacy "do not retry after unexpected
 	// HTTP error" subtests are only run when this capability is absent.
 	CapabilityRetryConformanceFDv1Polling = "retry-conformance-fdv1-polling"
+
+	// CapabilityDataSourceStatus indicates that the test service supports the getDataSourceStatus
+	// and registerDataSourceStatusListener commands, which expose the SDK's data source status
+	// provider.
+	CapabilityDataSourceStatus = "data-source-status"
+
+	// CapabilityFlagChangeListeners indicates that the test service supports the
+	// registerFlagChangeListener command, which exposes the SDK's flag change notifications.
+	CapabilityFlagChangeListeners = "flag-change-listeners"
+
+	// CapabilityFDv2 indicates that the SDK supports the FDv2 data system, configured with the
+	// "dataSystem" property of the SDK configuration. This means that it can connect to the FDv2
+	// streaming and polling endpoints, process "server-intent", "put-object", "delete-object",
+	// "payload-transferred", and "goodbye" events, and send its last known selector in the "basis"
+	// query parameter when it reconnects.
+	CapabilityFDv2 = "fdv2"
+
+	// CapabilityStreamingReadTimeout indicates that the SDK supports the "readTimeoutMs" property of
+	// the streaming configuration: if no data, not even a heartbeat comment, is received on the stream
+	// for that long, the SDK treats the connection as dead and reconnects.
+	CapabilityStreamingReadTimeout = "streaming-read-timeout"
+
+	// CapabilityStreamingLastEventID indicates that a server-side SDK sends the ID of the last stream
+	// event it received in the Last-Event-ID header when it reconnects to the stream, and correctly
+	// handles a reconnection where the stream resumes after that event instead of resending all data.
+	CapabilityStreamingLastEventID = "streaming-last-event-id"
+
+	// CapabilityConnectionMode indicates that a client-side test service supports the setOffline and
+	// setConnectionMode commands, which put the SDK offline or back online, and switch it between
+	// streaming, polling, foreground, and background modes at runtime.
+	CapabilityConnectionMode = "connection-mode"
+
+	// CapabilityBootstrap indicates that a client-side SDK can be configured to start with flag data
+	// that was produced by a server-side SDK's allFlagsState method, as in the "bootstrap" option of the
+	// JS-based client-side SDKs.
+	CapabilityBootstrap = "bootstrap"
+
+	// CapabilityClientFlagCache indicates that a client-side SDK caches the flags it has received for each
+	// context in persistent storage, and that the test service supports the "storageNamespace" and
+	// "maxCachedContexts" properties of the client-side configuration.
+	CapabilityClientFlagCache = "client-flag-cache"
+
+	// CapabilityFileDataSource indicates that a server-side SDK has a file data source, which the test
+	// service will enable if the "fileDataSource" configuration property is set.
+	CapabilityFileDataSource = "file-data-source"
+
+	// CapabilityDataStoreStatus indicates that the test service supports the getDataStoreStatus command,
+	// which exposes the status of the SDK's persistent data store.
+	CapabilityDataStoreStatus = "data-store-status"
 )
 
+// AllCapabilities returns every capability that is defined in this package. The test harness uses
+// this to report on capability coverage, and to detect capabilities reported by a test service
+// that the test harness does not know about.
+func AllCapabilities() []string {
+	return []string{
+		CapabilityClientSide,
+		CapabilityServerSide,
+		CapabilityStronglyTyped,
+		CapabilityMobile,
+		CapabilityPHP,
+		CapabilityRoku,
+		CapabilitySingleton,
+		CapabilityClientIndependence,
+		CapabilityAllFlagsWithReasons,
+		CapabilityAllFlagsClientSideOnly,
+		CapabilityAllFlagsDetailsOnlyForTrackedFlags,
+		CapabilityBigSegments,
+		CapabilityContextType,
+		CapabilityContextComparison,
+		CapabilitySecureModeHash,
+		CapabilityServerSidePolling,
+		CapabilityServiceEndpoints,
+		CapabilityTags,
+		CapabilityUserType,
+		CapabilityFiltering,
+		CapabilityFilteringStrict,
+		CapabilityAutoEnvAttributes,
+		CapabilityMigrations,
+		CapabilityEventSampling,
+		CapabilityEventGzip,
+		CapabilityOptionalEventGzip,
+		CapabilityETagCaching,
+		CapabilityInlineContext,
+		CapabilityInlineContextAll,
+		CapabilityInstanceID,
+		CapabilityAnonymousRedaction,
+		CapabilityPollingGzip,
+		CapabilityEvaluationHooks,
+		CapabilityTrackHooks,
+		CapabilityHookEnvironmentID,
+		CapabilityClientPrereqEvents,
+		CapabilityClientPrereqCycleDetection,
+		CapabilityPersistentDataStoreRedis,
+		CapabilityPersistentDataStoreConsul,
+		CapabilityPersistentDataStoreDynamoDB,
+		CapabilityClientPerContextSummaries,
+		CapabilityDiagnosticEvents,
+		CapabilityTLSVerifyPeer,
+		CapabilityTLSSkipVerifyPeer,
+		CapabilityTLSCustomCA,
+		CapabilityClientEventSourceHTTPErrors,
+		CapabilityOmitAnonymousContexts,
+		CapabilityWrapper,
+		CapabilityHTTPProxy,
+		CapabilityRetryConformanceFDv1Streaming,
+		CapabilityRetryConformanceFDv1Polling,
+		CapabilityDataSourceStatus,
+		CapabilityFlagChangeListeners,
+		CapabilityFDv2,
+		CapabilityStreamingReadTimeout,
+		CapabilityStreamingLastEventID,
+		CapabilityConnectionMode,
+		CapabilityBootstrap,
+		CapabilityClientFlagCache,
+		CapabilityFileDataSource,
+		CapabilityDataStoreStatus,
+	}
+}
+
 type StatusRep struct {
 	serviceinfo.TestServiceInfo
 	ClientVersion string `json:"clientVersion"`
@@ -0,0 +1,37 @@
+package servicedef
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllCapabilitiesIncludesEveryCapabilityConstant(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "service_params.go", nil, 0)
+	require.NoError(t, err)
+
+	all := make(map[string]bool)
+	for _, c := range AllCapabilities() {
+		all[c] = true
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for i, name := range spec.(*ast.ValueSpec).Names {
+				if !strings.HasPrefix(name.Name, "Capability") {
+					continue
+				}
+				value := spec.(*ast.ValueSpec).Values[i].(*ast.BasicLit).Value
+				assert.True(t, all[strings.Trim(value, `"`)], "AllCapabilities() is missing %s", name.Name)
+			}
+		}
+	}
+}
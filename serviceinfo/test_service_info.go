@@ -1,7 +1,11 @@
 // Package serviceinfo provides a data model for information provided by a service under test.
 package serviceinfo
 
-import "github.com/launchdarkly/sdk-test-harness/v2/framework"
+import (
+	"encoding/json"
+
+	"github.com/launchdarkly/sdk-test-harness/v2/framework"
+)
 
 // TestServiceInfo is status information returned by the test service from the initial status query.
 type TestServiceInfo struct {
@@ -24,3 +28,12 @@ type TestServiceInfoBase struct {
 func Empty() TestServiceInfo {
 	return TestServiceInfo{}
 }
+
+// Parse parses the status information returned by a test service.
+func Parse(data []byte) (TestServiceInfo, error) {
+	var base TestServiceInfoBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return Empty(), err
+	}
+	return TestServiceInfo{TestServiceInfoBase: base, FullData: data}, nil
+}